@@ -1,14 +1,16 @@
 package chimera
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/invopop/jsonschema"
-	"github.com/swaggest/swgui/v5emb"
 )
 
 var (
@@ -20,6 +22,7 @@ type APIError struct {
 	StatusCode int
 	Body       []byte
 	Header     http.Header
+	param      *ParamError
 }
 
 // Error returns the string representation of the error
@@ -38,6 +41,8 @@ type Nil struct{}
 // API is a collection of routes and middleware with an associated OpenAPI spec
 type API struct {
 	openAPISpec OpenAPI
+	servedSpec  *OpenAPI
+	mergedSpec  OpenAPI
 	router      *chi.Mux
 	routes      []*route
 	middleware  []MiddlewareFunc
@@ -45,6 +50,32 @@ type API struct {
 	basePath    string
 	parent      *API
 	staticPaths map[string]string
+	docs        DocsOptions
+
+	operationIDFunc   OperationIDFunc
+	operationDefaults OperationDefaults
+	cookieKeys        *CookieKeyRing
+	verifyJWT         JWTVerifier
+	groupParams       *groupParams
+	providers         map[reflect.Type]*provider
+
+	startupHooks  []LifecycleFunc
+	shutdownHooks []LifecycleFunc
+}
+
+// withParamsContext adds the cookie keys and JWT verifier of an API to the context of a request so its params can be read
+func (a *API) withParamsContext(r *http.Request) *http.Request {
+	ctx := r.Context()
+	if keys := a.cookieKeyRing(); keys != nil {
+		ctx = ContextWithCookieKeys(ctx, keys)
+	}
+	if verifier := a.jwtVerifier(); verifier != nil {
+		ctx = context.WithValue(ctx, jwtVerifierContextKey{}, verifier)
+	}
+	if ctx == r.Context() {
+		return r
+	}
+	return r.WithContext(ctx)
 }
 
 // OpenAPISpec returns the underlying OpenAPI structure for this API
@@ -57,11 +88,15 @@ func (a *API) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	customWriter := httpResponseWriter{
 		writer: w,
 	}
+	defer customWriter.cleanupDependencies()
 	a.router.ServeHTTP(&customWriter, req)
 	write(&customWriter, w, req)
 }
 
 func writeError(e error, w http.ResponseWriter) {
+	if err, ok := e.(ParamsError); ok {
+		e = err.APIError()
+	}
 	if err, ok := e.(APIError); ok {
 		for k, vals := range err.Header {
 			for _, v := range vals {
@@ -89,6 +124,7 @@ func write(customWriter *httpResponseWriter, w http.ResponseWriter, req *http.Re
 			head := ResponseHead{
 				StatusCode: customWriter.route.context.responseCode,
 				Headers:    customWriter.Header(),
+				cookieKeys: customWriter.route.api.cookieKeyRing(),
 			}
 			err := customWriter.response.WriteHead(&head)
 			if err != nil {
@@ -119,14 +155,14 @@ func write(customWriter *httpResponseWriter, w http.ResponseWriter, req *http.Re
 }
 
 // Start uses http.ListenAndServe to start serving requests from addr
+// (see Serve for timeouts, TLS and graceful shutdown)
 func (a *API) Start(addr string) error {
 	return http.ListenAndServe(addr, a)
 }
 
 // NewAPI returns an initialized API object
-func NewAPI() *API {
+func NewAPI(opts ...APIOption) *API {
 	api := API{
-		router: chi.NewRouter(),
 		openAPISpec: OpenAPI{
 			OpenAPI: "3.1.0",
 			Paths:   make(map[string]Path),
@@ -140,57 +176,76 @@ func NewAPI() *API {
 			},
 		},
 	}
+	WithDocs(DocsOptions{})(&api)
+	for _, opt := range opts {
+		opt(&api)
+	}
+	api.rebuildRouter()
 	return &api
 }
 
-// addRoute creates a route based on method, path, handler, etc.
-func addRoute[ReqPtr RequestReaderPtr[Req], Req any, RespPtr ResponseWriterPtr[Resp], Resp any](api *API, method, path string, handler HandlerFunc[ReqPtr, Req, RespPtr, Resp]) Route {
-	if path == "" || path[0] != '/' {
-		path = "/" + path
-	}
-
-	reqSchema := ReqPtr(new(Req)).OpenAPIRequestSpec()
-	operation := Operation{
-		RequestSpec: &reqSchema,
-		Responses:   RespPtr(new(Resp)).OpenAPIResponsesSpec(),
-	}
-
-	if reqSchema.RequestBody != nil {
-		for k, v := range reqSchema.RequestBody.Content {
+// standardizeOperationSchemas moves the definitions of every schema in an operation to components.schemas
+func (a *API) standardizeOperationSchemas(operation *Operation) {
+	if operation.RequestSpec != nil && operation.RequestSpec.RequestBody != nil {
+		for k, v := range operation.RequestSpec.RequestBody.Content {
 			if v.Schema != nil {
-				// TODO: maybe implement a global schema resolver?
-				// otherwise some classes with the same name may clobber
-				// eachother in the final spec
-				standardizedSchemas(v.Schema, api.openAPISpec.Components.Schemas)
+				standardizedSchemas(v.Schema, a.openAPISpec.Components.Schemas)
 			}
-			reqSchema.RequestBody.Content[k] = v
+			operation.RequestSpec.RequestBody.Content[k] = v
 		}
 	}
 	if operation.Responses != nil {
 		for c, r := range operation.Responses {
 			for k, v := range r.Content {
 				if v.Schema != nil {
-					standardizedSchemas(v.Schema, api.openAPISpec.Components.Schemas)
+					standardizedSchemas(v.Schema, a.openAPISpec.Components.Schemas)
 				}
 				r.Content[k] = v
 			}
 			for k, v := range r.Headers {
 				if v.Schema != nil {
-					standardizedSchemas(v.Schema, api.openAPISpec.Components.Schemas)
+					standardizedSchemas(v.Schema, a.openAPISpec.Components.Schemas)
 				}
 				r.Headers[k] = v
 			}
 			operation.Responses[c] = r
 		}
 	}
-	if reqSchema.Parameters != nil {
-		for i, p := range reqSchema.Parameters {
+	if operation.RequestSpec != nil && operation.RequestSpec.Parameters != nil {
+		for i, p := range operation.RequestSpec.Parameters {
 			if p.Schema != nil {
-				standardizedSchemas(p.Schema, api.openAPISpec.Components.Schemas)
+				standardizedSchemas(p.Schema, a.openAPISpec.Components.Schemas)
+			}
+			operation.RequestSpec.Parameters[i] = p
+		}
+	}
+}
+
+// addRoute creates a route based on method, path, handler, etc.
+func addRoute[ReqPtr RequestReaderPtr[Req], Req any, RespPtr ResponseWriterPtr[Resp], Resp any](api *API, method, path string, handler HandlerFunc[ReqPtr, Req, RespPtr, Resp]) Route {
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+
+	reqSchema := ReqPtr(new(Req)).OpenAPIRequestSpec()
+	operation := Operation{
+		RequestSpec: &reqSchema,
+		Responses:   RespPtr(new(Resp)).OpenAPIResponsesSpec(),
+	}
+	deps := newDependencies(api, reflect.TypeOf(new(Req)).Elem())
+	if deps != nil {
+		// the params and security of dependencies are part of the operation too
+		for _, param := range deps.parameters {
+			if !hasParameter(reqSchema.Parameters, param) {
+				reqSchema.Parameters = append(reqSchema.Parameters, param)
 			}
-			reqSchema.Parameters[i] = p
 		}
 	}
+
+	api.standardizeOperationSchemas(&operation)
+	if err := validateOperationExamples(&operation, api.openAPISpec.Components.Schemas); err != nil {
+		panic("chimera: " + method + " " + path + " has " + err.Error())
+	}
 	pathSchema := Path{}
 	if p, ok := api.openAPISpec.Paths[path]; ok {
 		pathSchema = p
@@ -265,7 +320,7 @@ func addRoute[ReqPtr RequestReaderPtr[Req], Req any, RespPtr ResponseWriterPtr[R
 		}
 		pathSchema.Put = &operation
 	}
-	api.openAPISpec.Paths[api.basePath+path] = pathSchema
+	api.openAPISpec.Paths[path] = pathSchema
 
 	route := route{
 		operationSpec: &operation,
@@ -274,22 +329,45 @@ func addRoute[ReqPtr RequestReaderPtr[Req], Req any, RespPtr ResponseWriterPtr[R
 			responseCode: responseCode,
 			method:       method,
 			path:         path,
+			api:          api,
 		},
-		api: api,
+		api:         api,
+		handlerFunc: handler,
 	}
+	if deps != nil {
+		route.requiredSecurity = deps.security
+	}
+	route.defaultOperationID = api.operationID(method, path, handler)
+	operation.OperationID = route.defaultOperationID
 	chiHandler := (func(w http.ResponseWriter, r *http.Request) {
 		request := ReqPtr(new(Req))
+		r = api.withParamsContext(r)
 		customWriter := w.(*httpResponseWriter)
 		customWriter.route = &route
 		customWriter.respError = request.ReadRequest(r)
 		if customWriter.respError != nil {
 			return
 		}
+		if deps != nil {
+			customWriter.dependencies = &dependencyResolver{
+				deps:    deps,
+				request: r,
+				ctx:     customWriter.routeContext(),
+			}
+			customWriter.respError = deps.inject(customWriter.dependencies, reflect.ValueOf(request).Elem())
+			if customWriter.respError != nil {
+				return
+			}
+		}
 		customWriter.response, customWriter.respError = handler(request)
 	})
 	route.handler = chiHandler
 
 	api.routes = append(api.routes, &route)
+	if err := findDuplicateOperationID(api); err != nil {
+		api.routes = api.routes[:len(api.routes)-1]
+		panic(err.Error())
+	}
 	rebuildAPI(api)
 	return Route{
 		route: &route,
@@ -298,7 +376,7 @@ func addRoute[ReqPtr RequestReaderPtr[Req], Req any, RespPtr ResponseWriterPtr[R
 
 func rebuildAPI(api *API) {
 	a := api
-	for ; a.parent != nil; a = api.parent {
+	for ; a.parent != nil; a = a.parent {
 	}
 	a.rebuildRouter()
 }
@@ -367,14 +445,19 @@ func (a *API) Use(middleware ...MiddlewareFunc) {
 
 // Group creates a sub-API with seperate middleware and routes using a base path.
 // The middleware of the parent API is always evaluated first and any route collisions
-// are handled by chi directly
-func (a *API) Group(basePath string) *API {
+// are handled by chi directly. The options are applied to the sub-API (i.e. WithGroupParams).
+// Calling Group again with the same base path returns the existing sub-API, options can only be
+// passed when the sub-API is created (otherwise it panics)
+func (a *API) Group(basePath string, opts ...APIOption) *API {
 	for _, sub := range a.subAPIs {
-		if sub.basePath == a.basePath+basePath {
-			return a
+		if sub.basePath == basePath {
+			if len(opts) > 0 {
+				panic("chimera: group " + basePath + " already exists, its options can only be set when it is created")
+			}
+			return sub
 		}
 	}
-	newSub := NewAPI()
+	newSub := NewAPI(opts...)
 	a.Mount(basePath, newSub)
 	return newSub
 }
@@ -384,6 +467,12 @@ func (a *API) Mount(basePath string, subAPI *API) {
 	subAPI.basePath = basePath
 	subAPI.parent = a
 	a.subAPIs = append(a.subAPIs, subAPI)
+	subAPI.refreshOperationIDs()
+	if err := findDuplicateOperationID(a); err != nil {
+		a.subAPIs = a.subAPIs[:len(a.subAPIs)-1]
+		subAPI.parent = nil
+		panic(err.Error())
+	}
 	rebuildAPI(a)
 }
 
@@ -391,7 +480,12 @@ func (a *API) Mount(basePath string, subAPI *API) {
 // but at least this allows us to specify middleware/routes/groups in any order
 // while still having a guaranteed final order
 func (a *API) rebuildRouter() chi.Router {
-	var schema []byte
+	// the specs are marshaled once when they are first requested (requests can be concurrent),
+	// the operation defaults are applied then so changes made to routes until then are included
+	var schema, yamlSchema, schema30 []byte
+	var schemaOnce, yamlSchemaOnce, schema30Once sync.Once
+	// types may have been renamed since their schemas were added (once another type wanted the same name)
+	registry.renameSchemas(&a.openAPISpec)
 	apiSpec := OpenAPI{
 		OpenAPI: "3.1.0",
 		Paths:   make(map[string]Path),
@@ -412,26 +506,51 @@ func (a *API) rebuildRouter() chi.Router {
 
 	router := chi.NewRouter()
 	if a.parent == nil {
-		router.MethodFunc(http.MethodGet, "/openapi.json", func(w http.ResponseWriter, r *http.Request) {
-			if schema == nil {
-				schema, _ = json.Marshal(apiSpec)
+		a.servedSpec = &apiSpec
+		docs := a.docs
+		if !docs.DisableSpec {
+			router.Method(http.MethodGet, docs.SpecPath, docs.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				schemaOnce.Do(func() {
+					schema, _ = json.Marshal(a.withOperationDefaults(&apiSpec))
+				})
+				w.Header().Set("Content-Type", "application/json")
+				w.Write(schema)
+			})))
+			router.Method(http.MethodGet, docs.yamlSpecPath(), docs.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				yamlSchemaOnce.Do(func() {
+					yamlSchema, _ = MarshalSpec(a.withOperationDefaults(&apiSpec), SpecFormatYAML)
+				})
+				w.Header().Set("Content-Type", "application/yaml")
+				w.Write(yamlSchema)
+			})))
+			if docs.Spec30Path != "" {
+				router.Method(http.MethodGet, docs.Spec30Path, docs.wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					schema30Once.Do(func() {
+						doc, _ := a.withOperationDefaults(&apiSpec).As30()
+						schema30, _ = json.Marshal(doc)
+					})
+					w.Header().Set("Content-Type", "application/json")
+					w.Write(schema30)
+				})))
 			}
-			w.Write(schema)
-		})
-		router.Handle("/docs*",
-			v5emb.New(
-				apiSpec.Info.Title,
-				"/openapi.json",
-				"/docs",
-			),
-		)
+		}
+		if !docs.DisableSpec && !docs.DisableUI {
+			router.Handle(strings.TrimSuffix(docs.UIPath, "/")+"*", docs.wrap(
+				docs.UI(
+					apiSpec.Info.Title,
+					docs.SpecPath,
+					docs.UIPath,
+					docs.UIConfig,
+				),
+			))
+		}
 	}
 	for _, sub := range a.subAPIs {
 		if sub.basePath == "" || sub.basePath[0] != '/' {
 			sub.basePath = "/" + sub.basePath
 		}
 		router.Mount(sub.basePath, sub.rebuildRouter())
-		apiSpec.Merge(sub.openAPISpec)
+		apiSpec.Merge(sub.mergedSpec)
 	}
 	for apiPath, filesPath := range a.staticPaths {
 		fileServer := http.FileServer(http.Dir(filesPath))
@@ -439,6 +558,7 @@ func (a *API) rebuildRouter() chi.Router {
 	}
 
 	middlewareChain := make([]MiddlewareFunc, 0)
+	groupChain := a.groupParamsChain()
 	for api := a; api != nil; api = api.parent {
 		middlewareChain = append(api.middleware, middlewareChain...)
 	}
@@ -457,7 +577,7 @@ func (a *API) rebuildRouter() chi.Router {
 					writer:  w,
 					handler: h,
 				}
-				return middleware(r, w.route.context, wrapped.Next)
+				return middleware(r, w.routeContext(), wrapped.Next)
 			})
 		// case HttpMiddlewareFunc:
 		// 	next := func(w http.ResponseWriter, req *http.Request) {
@@ -539,10 +659,17 @@ func (a *API) rebuildRouter() chi.Router {
 		if route.context.path == "" || route.context.path[0] != '/' {
 			route.context.path = "/" + route.context.path
 		}
-		if len(middlewareChain) > 0 {
+		if len(middlewareChain) > 0 || len(groupChain) > 0 {
 			router.MethodFunc(route.context.method, route.context.path, func(w http.ResponseWriter, r *http.Request) {
 				writer := w.(*httpResponseWriter)
 				writer.route = route
+				if len(groupChain) > 0 {
+					// group params are parsed once before any middleware
+					writer.groupParams, writer.respError = unmarshalGroupParams(route.api.withParamsContext(r), groupChain)
+					if writer.respError != nil {
+						return
+					}
+				}
 				writer.response, writer.respError = handler(writer, r)
 			})
 		} else {
@@ -550,6 +677,28 @@ func (a *API) rebuildRouter() chi.Router {
 		}
 
 	}
+	if a.parent != nil {
+		// the parent merges this in to its own spec so paths need to include the base path
+		a.mergedSpec = apiSpec
+		a.mergedSpec.Paths = make(map[string]Path, len(apiSpec.Paths))
+		for path, obj := range apiSpec.Paths {
+			a.mergedSpec.Paths[a.basePath+path] = obj
+		}
+		if a.groupParams != nil {
+			a.groupParams.withGroupParameters(a.mergedSpec.Paths)
+		}
+	}
+	if a.parent == nil {
+		// the paths are shared with a.openAPISpec which has to keep the original path parameters
+		paths := make(map[string]Path, len(apiSpec.Paths))
+		for path, obj := range apiSpec.Paths {
+			paths[path] = obj
+		}
+		if a.groupParams != nil {
+			a.groupParams.withGroupParameters(paths)
+		}
+		apiSpec.Paths = paths
+	}
 	a.router = router
 	return router
 }
@@ -1,7 +1,10 @@
 package chimera_test
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"testing"
 
 	"github.com/matt1484/chimera"
 	"github.com/stretchr/testify/assert"
@@ -70,3 +73,26 @@ type TestStructParams struct {
 	StringProp string `prop:"stringprop"`
 	IntProp    int    `prop:"intprop"`
 }
+
+func TestNestedGroups(t *testing.T) {
+	api := chimera.NewAPI()
+	inner := api.Group("/a").Group("/b")
+	// routes can be added to groups at any depth
+	chimera.Get(inner, "/c", func(*chimera.EmptyRequest) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/a/b/c", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// their paths include the base path of every group
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	spec := chimera.OpenAPI{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	paths := make([]string, 0)
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	assert.Equal(t, []string{"/a/b/c"}, paths)
+}
@@ -14,6 +14,10 @@ var (
 	_ ResponseWriter = new(BinaryResponse[Nil])
 	_ RequestReader  = new(Binary[Nil])
 	_ ResponseWriter = new(Binary[Nil])
+	_ RequestWriter  = new(BinaryRequest[Nil])
+	_ ResponseReader = new(BinaryResponse[Nil])
+	_ RequestWriter  = new(Binary[Nil])
+	_ ResponseReader = new(Binary[Nil])
 )
 
 // BinaryRequest[Params any] is a request type that uses a
@@ -32,6 +36,11 @@ func (r *BinaryRequest[Params]) Context() context.Context {
 	return nil
 }
 
+// httpRequest returns the original http.Request (see Callback.URL)
+func (r *BinaryRequest[Params]) httpRequest() *http.Request {
+	return r.request
+}
+
 func readBinaryRequest[Params any](req *http.Request, body *[]byte, params *Params) error {
 	defer req.Body.Close()
 	b, err := io.ReadAll(req.Body)
@@ -58,6 +67,33 @@ func (r *BinaryRequest[Params]) ReadRequest(req *http.Request) error {
 	return readBinaryRequest(req, &r.Body, &r.Params)
 }
 
+func writeBinaryRequest[Params any](req *http.Request, body *[]byte, params *Params) error {
+	setRequestBody(req, "application/octet-stream", *body)
+
+	if _, ok := any(params).(*Nil); !ok {
+		return MarshalRequestParams(req, params)
+	}
+	return nil
+}
+
+// WriteRequest writes the Body field to the request and the Params field using MarshalRequestParams
+func (r *BinaryRequest[Params]) WriteRequest(req *http.Request) error {
+	return writeBinaryRequest(req, &r.Body, &r.Params)
+}
+
+func readBinaryResponse[Params any](resp *http.Response, body *[]byte, params *Params) error {
+	b, err := readResponseBody(resp)
+	if err != nil {
+		return err
+	}
+	*body = b
+
+	if _, ok := any(params).(*Nil); !ok {
+		return UnmarshalResponseParams(resp, params)
+	}
+	return nil
+}
+
 func binaryRequestSpec[Params any](schema *RequestSpec) {
 	schema.RequestBody = &RequestBody{
 		Content: map[string]MediaType{
@@ -141,16 +177,13 @@ func (r *BinaryResponse[Params]) OpenAPIResponsesSpec() Responses {
 // WriteHead writes adds the header for this response object
 func (r *BinaryResponse[Params]) WriteHead(head *ResponseHead) error {
 	head.Headers.Set("Content-Type", "application/octet-stream")
-	h, err := MarshalParams(&r.Params)
-	if err != nil {
-		return err
-	}
-	for k, v := range h {
-		for _, x := range v {
-			head.Headers.Add(k, x)
-		}
-	}
-	return nil
+	return head.MarshalParams(&r.Params)
+}
+
+// ReadResponse reads the response body into the Body field
+// and the response headers into the Params field using UnmarshalResponseParams
+func (r *BinaryResponse[Params]) ReadResponse(resp *http.Response) error {
+	return readBinaryResponse(resp, &r.Body, &r.Params)
 }
 
 // NewBinaryResponse creates a BinaryResponse from body and params
@@ -178,6 +211,11 @@ func (r *Binary[Params]) Context() context.Context {
 	return nil
 }
 
+// httpRequest returns the original http.Request (see Callback.URL)
+func (r *Binary[Params]) httpRequest() *http.Request {
+	return r.request
+}
+
 // ReadRequest reads the body of an http request and assigns it to the Body field using io.ReadAll.
 // This function also reads the parameters using UnmarshalParams and assigns it to the Params field.
 // NOTE: the body of the request is closed after this function is run.
@@ -186,6 +224,17 @@ func (r *Binary[Params]) ReadRequest(req *http.Request) error {
 	return readBinaryRequest(req, &r.Body, &r.Params)
 }
 
+// WriteRequest writes the Body field to the request and the Params field using MarshalRequestParams
+func (r *Binary[Params]) WriteRequest(req *http.Request) error {
+	return writeBinaryRequest(req, &r.Body, &r.Params)
+}
+
+// ReadResponse reads the response body into the Body field
+// and the response headers into the Params field using UnmarshalResponseParams
+func (r *Binary[Params]) ReadResponse(resp *http.Response) error {
+	return readBinaryResponse(resp, &r.Body, &r.Params)
+}
+
 // OpenAPIRequestSpec returns the Request definition of a BinaryRequest
 func (r *Binary[Params]) OpenAPIRequestSpec() RequestSpec {
 	schema := RequestSpec{}
@@ -209,14 +258,5 @@ func (r *Binary[Params]) OpenAPIResponsesSpec() Responses {
 // WriteHead writes the header for this response object
 func (r *Binary[Params]) WriteHead(head *ResponseHead) error {
 	head.Headers.Set("Content-Type", "application/octet-stream")
-	h, err := MarshalParams(&r.Params)
-	if err != nil {
-		return err
-	}
-	for k, v := range h {
-		for _, x := range v {
-			head.Headers.Add(k, x)
-		}
-	}
-	return nil
+	return head.MarshalParams(&r.Params)
 }
@@ -0,0 +1,246 @@
+package chimera
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Callback is a callback documented on a route that can be invoked using the request the route received
+type Callback[Req, Resp any] struct {
+	name       string
+	expression string
+	method     string
+	route      *route
+	operation  *Operation
+	do         func(ctx context.Context, client *Client, payload *Req) (*Resp, error)
+}
+
+// WithCallback documents a callback on a route (in its operation's callbacks) using the payload (Req) and
+// response (Resp) types, the same way routes are documented. expression is the runtime expression of the url
+// the callback is sent to (i.e. {$request.body#/callbackUrl}). The returned Callback is used to invoke it.
+func WithCallback[Req, Resp any, ReqPtr WebhookRequestPtr[Req], RespPtr WebhookResponsePtr[Resp]](r Route, name, expression, method string) *Callback[Req, Resp] {
+	operation := typedOperation[Req, Resp, ReqPtr, RespPtr](r.route.api)
+	if r.route.operationSpec.Callbacks == nil {
+		r.route.operationSpec.Callbacks = make(map[string]map[string]Path)
+	}
+	if r.route.operationSpec.Callbacks[name] == nil {
+		r.route.operationSpec.Callbacks[name] = make(map[string]Path)
+	}
+	path := r.route.operationSpec.Callbacks[name][expression]
+	path.SetOperation(method, operation)
+	r.route.operationSpec.Callbacks[name][expression] = path
+	rebuildAPI(r.route.api)
+
+	return &Callback[Req, Resp]{
+		name:       name,
+		expression: expression,
+		method:     method,
+		route:      r.route,
+		operation:  operation,
+		do: func(ctx context.Context, client *Client, payload *Req) (*Resp, error) {
+			return Do[Resp, RespPtr](ctx, client, method, "", ReqPtr(payload))
+		},
+	}
+}
+
+// Name returns the name of the callback
+func (c *Callback[Req, Resp]) Name() string {
+	return c.name
+}
+
+// OpenAPIOperationSpec returns the Operation spec for this callback
+func (c *Callback[Req, Resp]) OpenAPIOperationSpec() *Operation {
+	return c.operation
+}
+
+// WithOperation performs a merge on the operation's spec for this callback
+func (c *Callback[Req, Resp]) WithOperation(op Operation) *Callback[Req, Resp] {
+	c.operation.Merge(op)
+	return c
+}
+
+// inboundRequest is implemented by request types that keep the http.Request they were read from
+type inboundRequest interface {
+	httpRequest() *http.Request
+}
+
+// URL resolves the callback's expression using the request the route received (i.e. the request passed to the handler).
+// $url, $method, path, query and header expressions are resolved against the original http.Request (if the request type
+// keeps it, like JSON or PlainText) and body expressions against the body of request (since the original was already read).
+// Other request types (i.e. NoBodyRequest) are resolved against the route's path and whatever they write to a request.
+func (c *Callback[Req, Resp]) URL(request RequestWriter) (string, error) {
+	path := c.route.api.fullPath(c.route.context.path)
+	req, err := http.NewRequest(c.route.context.method, "http://localhost", http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	req.URL.Path = path
+	if request != nil {
+		if err := request.WriteRequest(req); err != nil {
+			return "", err
+		}
+	}
+	if r, ok := request.(inboundRequest); ok && r.httpRequest() != nil {
+		inbound := r.httpRequest()
+		u := *inbound.URL
+		if u.Host == "" {
+			u.Host = inbound.Host
+		}
+		if u.Scheme == "" {
+			u.Scheme = "http"
+			if inbound.TLS != nil {
+				u.Scheme = "https"
+			}
+		}
+		req.URL = &u
+		req.Method = inbound.Method
+		req.Header = inbound.Header
+	}
+	return resolveRuntimeExpression(c.expression, path, req)
+}
+
+// Invoke sends payload to the url resolved from the request the route received using dispatcher
+// (so failed deliveries are retried, see WebhookDispatcher)
+func (c *Callback[Req, Resp]) Invoke(ctx context.Context, dispatcher *WebhookDispatcher, request RequestWriter, payload *Req) (*Resp, error) {
+	u, err := c.URL(request)
+	if err != nil {
+		return nil, err
+	}
+	var resp *Resp
+	err = dispatcher.deliver(ctx, u, func(client *Client) error {
+		var err error
+		resp, err = c.do(ctx, client, payload)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// resolveRuntimeExpression replaces every {expression} in expression with its value from req,
+// template is the path the route was defined with (used to find path params)
+func resolveRuntimeExpression(expression, template string, req *http.Request) (string, error) {
+	resolved := strings.Builder{}
+	var body any
+	bodyRead := false
+	for {
+		start := strings.Index(expression, "{")
+		if start < 0 {
+			resolved.WriteString(expression)
+			break
+		}
+		end := strings.Index(expression[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("chimera: unterminated runtime expression in %q", expression)
+		}
+		end += start
+		resolved.WriteString(expression[:start])
+		expr := expression[start+1 : end]
+		expression = expression[end+1:]
+
+		var value string
+		switch {
+		case expr == "$url":
+			value = req.URL.String()
+		case expr == "$method":
+			value = req.Method
+		case strings.HasPrefix(expr, "$request.path."):
+			value = pathParamValue(template, req.URL.Path, strings.TrimPrefix(expr, "$request.path."))
+		case strings.HasPrefix(expr, "$request.query."):
+			value = req.URL.Query().Get(strings.TrimPrefix(expr, "$request.query."))
+		case strings.HasPrefix(expr, "$request.header."):
+			value = req.Header.Get(strings.TrimPrefix(expr, "$request.header."))
+		case expr == "$request.body" || strings.HasPrefix(expr, "$request.body#"):
+			if !bodyRead {
+				bodyRead = true
+				if req.Body != nil {
+					b, err := io.ReadAll(req.Body)
+					if err != nil {
+						return "", err
+					}
+					if len(bytes.TrimSpace(b)) > 0 {
+						if err := json.Unmarshal(b, &body); err != nil {
+							return "", err
+						}
+					}
+				}
+			}
+			found, err := resolveJSONPointer(body, strings.TrimPrefix(strings.TrimPrefix(expr, "$request.body"), "#"))
+			if err != nil {
+				return "", fmt.Errorf("chimera: could not resolve %s: %w", expr, err)
+			}
+			if s, ok := found.(string); ok {
+				value = s
+			} else {
+				b, err := json.Marshal(found)
+				if err != nil {
+					return "", err
+				}
+				value = string(b)
+			}
+		default:
+			return "", fmt.Errorf("chimera: unsupported runtime expression %s", expr)
+		}
+		resolved.WriteString(value)
+	}
+	return resolved.String(), nil
+}
+
+// pathParamValue finds the value of a path param by matching path against the template it was written to,
+// segments are matched from the end since path can have a prefix (i.e. when the API is mounted)
+func pathParamValue(template, path, name string) string {
+	templateSegments := strings.Split(template, "/")
+	pathSegments := strings.Split(path, "/")
+	offset := len(pathSegments) - len(templateSegments)
+	if offset < 0 {
+		return ""
+	}
+	for i, segment := range templateSegments {
+		if segment == "{"+name+"}" {
+			value, err := url.PathUnescape(pathSegments[offset+i])
+			if err != nil {
+				return pathSegments[offset+i]
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+// resolveJSONPointer finds the value at pointer (i.e. /items/0/url) in a decoded JSON document
+func resolveJSONPointer(doc any, pointer string) (any, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", token)
+			}
+			doc = value
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("index %q not found", token)
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("%q not found", token)
+		}
+	}
+	return doc, nil
+}
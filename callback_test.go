@@ -0,0 +1,101 @@
+package chimera_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestCallbackSubscription struct {
+	CallbackURL string `json:"callbackUrl"`
+}
+
+type TestCallbackParams struct {
+	Tenant string `param:"tenant,in=path"`
+}
+
+type TestCallbackEvent struct {
+	Tenant string `json:"tenant"`
+}
+
+func TestCallback(t *testing.T) {
+	received := make(chan string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := TestCallbackEvent{}
+		json.NewDecoder(r.Body).Decode(&event)
+		received <- r.URL.Path + ":" + event.Tenant
+	}))
+	defer receiver.Close()
+
+	api := chimera.NewAPI()
+	group := api.Group("/tenants")
+	var callback *chimera.Callback[chimera.JSON[TestCallbackEvent, chimera.Nil], chimera.EmptyResponse]
+	route := chimera.Post(group, "/{tenant}/subscriptions", func(req *chimera.JSON[TestCallbackSubscription, TestCallbackParams]) (*chimera.EmptyResponse, error) {
+		_, err := callback.Invoke(req.Context(), &chimera.WebhookDispatcher{}, req, &chimera.JSON[TestCallbackEvent, chimera.Nil]{
+			Body: TestCallbackEvent{Tenant: req.Params.Tenant},
+		})
+		return &chimera.EmptyResponse{}, err
+	})
+	callback = chimera.WithCallback[chimera.JSON[TestCallbackEvent, chimera.Nil], chimera.EmptyResponse](
+		route, "event", "{$request.body#/callbackUrl}/{$request.path.tenant}", http.MethodPost,
+	)
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+	resp, err := http.Post(server.URL+"/tenants/acme/subscriptions", "application/json", strings.NewReader(`{"callbackUrl":"`+receiver.URL+`/hooks"}`))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "/hooks/acme:acme", <-received)
+
+	resp, err = http.Get(server.URL + "/openapi.json")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	spec := chimera.OpenAPI{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+	op := spec.Paths["/tenants/{tenant}/subscriptions"].Post
+	assert.NotNil(t, op)
+	callbackOp := op.Callbacks["event"]["{$request.body#/callbackUrl}/{$request.path.tenant}"].Post
+	assert.NotNil(t, callbackOp)
+	assert.Equal(t, "#/components/schemas/TestCallbackEvent", callbackOp.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Contains(t, spec.Components.Schemas, "TestCallbackEvent")
+
+	// expressions that can't be resolved are errors
+	bad := chimera.WithCallback[chimera.JSON[TestCallbackEvent, chimera.Nil], chimera.EmptyResponse](route, "bad", "{$request.body#/missing}", http.MethodPost)
+	_, err = bad.URL(&chimera.JSON[TestCallbackSubscription, TestCallbackParams]{})
+	assert.Error(t, err)
+	_, err = bad.Invoke(context.Background(), &chimera.WebhookDispatcher{}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestCallbackURL(t *testing.T) {
+	api := chimera.NewAPI()
+	var callback *chimera.Callback[chimera.JSON[TestCallbackEvent, chimera.Nil], chimera.EmptyResponse]
+	route := chimera.Post(api, "/{tenant}/subscriptions", func(req *chimera.JSON[TestCallbackSubscription, TestCallbackParams]) (*chimera.PlainTextResponse[chimera.Nil], error) {
+		u, err := callback.URL(req)
+		return &chimera.PlainTextResponse[chimera.Nil]{Body: u}, err
+	})
+	callback = chimera.WithCallback[chimera.JSON[TestCallbackEvent, chimera.Nil], chimera.EmptyResponse](
+		route, "event", "{$request.body#/callbackUrl}?from={$url}&method={$method}&tenant={$request.path.tenant}&id={$request.header.X-Request-Id}", http.MethodPost,
+	)
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	// expressions are resolved against the request the route received
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/acme/subscriptions?verbose=true", strings.NewReader(`{"callbackUrl":"https://example.com/hooks"}`))
+	req.Header.Set("X-Request-Id", "abc")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body := strings.Builder{}
+	_, err = io.Copy(&body, resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/hooks?from="+server.URL+"/acme/subscriptions?verbose=true&method=POST&tenant=acme&id=abc", body.String())
+}
@@ -0,0 +1,241 @@
+// Package chimeratest provides helpers for calling chimera routes in-process
+// using the same request/response types that the routes are defined with
+package chimeratest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/matt1484/chimera"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// TB is the part of testing.TB used to report failures (*testing.T and *testing.B implement it)
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// Result is the result of calling a route with Call
+type Result[Resp any] struct {
+	t TB
+	// Method is the http method that was called
+	Method string
+	// Path is the path template that was called (i.e. /route/{param})
+	Path string
+	// Response is the raw response, its body has already been read into Body
+	Response *http.Response
+	// Body is the raw response body
+	Body []byte
+	// Value is the typed response (only valid if Err is nil)
+	Value *Resp
+	// Err is the error returned while reading the typed response
+	Err error
+	// Spec is the OpenAPI spec of the API that was called
+	Spec *chimera.OpenAPI
+}
+
+// Call writes req to an http.Request (params are written to the path template, query, headers and cookies),
+// serves it using api.ServeHTTP and reads the response into Resp. The path should be the path template
+// the route was registered with (i.e. /route/{param}) so that the Result can be checked against the spec.
+func Call[Resp any, RespPtr chimera.ResponseReaderPtr[Resp]](t TB, api *chimera.API, method, path string, req chimera.RequestWriter) *Result[Resp] {
+	t.Helper()
+	httpReq := httptest.NewRequest(method, "/", http.NoBody)
+	if keys := api.CookieKeys(); keys != nil {
+		// signed/encrypted cookies are written and read with the API's keys
+		httpReq = httpReq.WithContext(chimera.ContextWithCookieKeys(httpReq.Context(), keys))
+	}
+	template, query, _ := strings.Cut(path, "?")
+	httpReq.URL.Path = template
+	httpReq.URL.RawQuery = query
+	if req != nil {
+		if err := req.WriteRequest(httpReq); err != nil {
+			t.Fatalf("chimeratest: failed to write request: %v", err)
+			// only reached if t doesnt stop the test
+			return &Result[Resp]{
+				t:        t,
+				Method:   method,
+				Path:     template,
+				Response: &http.Response{Header: make(http.Header), Request: httpReq},
+				Err:      err,
+				Spec:     api.OpenAPISpec(),
+			}
+		}
+	}
+	httpReq.RequestURI = httpReq.URL.RequestURI()
+
+	recorder := httptest.NewRecorder()
+	api.ServeHTTP(recorder, httpReq)
+	resp := recorder.Result()
+	resp.Request = httpReq
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	value := RespPtr(new(Resp))
+	err := value.ReadResponse(resp)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	result := Result[Resp]{
+		t:        t,
+		Method:   method,
+		Path:     template,
+		Response: resp,
+		Body:     body,
+		Value:    (*Resp)(value),
+		Err:      err,
+		Spec:     api.OpenAPISpec(),
+	}
+	return &result
+}
+
+// StatusCode returns the status code of the response
+func (r *Result[Resp]) StatusCode() int {
+	return r.Response.StatusCode
+}
+
+// Operation returns the spec of the operation that was called (or nil if it isnt in the spec)
+func (r *Result[Resp]) Operation() *chimera.Operation {
+	if r.Spec == nil {
+		return nil
+	}
+	if path, ok := r.Spec.Paths[r.Path]; ok {
+		return path.Operation(r.Method)
+	}
+	return nil
+}
+
+// AssertStatus fails the test if the status code of the response is not code
+func (r *Result[Resp]) AssertStatus(code int) *Result[Resp] {
+	r.t.Helper()
+	if r.Response.StatusCode != code {
+		r.t.Errorf("chimeratest: expected status code %d but got %d: %s", code, r.Response.StatusCode, r.Body)
+	}
+	return r
+}
+
+// AssertHeader fails the test if the response header name does not have value
+func (r *Result[Resp]) AssertHeader(name, value string) *Result[Resp] {
+	r.t.Helper()
+	values := r.Response.Header.Values(name)
+	for _, v := range values {
+		if v == value {
+			return r
+		}
+	}
+	r.t.Errorf("chimeratest: expected header %s to be %q but got %q", name, value, values)
+	return r
+}
+
+// AssertNoError fails the test if the response could not be read into Resp
+func (r *Result[Resp]) AssertNoError() *Result[Resp] {
+	r.t.Helper()
+	if r.Err != nil {
+		r.t.Errorf("chimeratest: failed to read response: %v", r.Err)
+	}
+	return r
+}
+
+// AssertConformsToSpec fails the test if the response does not match the operation's spec, specifically:
+// the status code must be documented, required headers must be present, the content type must be documented
+// and json bodies must be valid against their schema
+func (r *Result[Resp]) AssertConformsToSpec() *Result[Resp] {
+	r.t.Helper()
+	for _, err := range r.conformanceErrors() {
+		r.t.Errorf("chimeratest: %s %s does not conform to spec: %v", r.Method, r.Path, err)
+	}
+	return r
+}
+
+// conformanceErrors gets all the differences between the response and the operation's spec
+func (r *Result[Resp]) conformanceErrors() []error {
+	op := r.Operation()
+	if op == nil {
+		return []error{fmt.Errorf("operation not found in spec")}
+	}
+	code := strconv.Itoa(r.Response.StatusCode)
+	spec, ok := op.Responses[code]
+	if !ok {
+		code = code[:1] + "XX"
+		if spec, ok = op.Responses[code]; !ok {
+			code = "default"
+			if spec, ok = op.Responses[code]; !ok {
+				return []error{fmt.Errorf("status code %d is not documented", r.Response.StatusCode)}
+			}
+		}
+	}
+
+	errs := make([]error, 0)
+	for name, header := range spec.Headers {
+		// response cookies all share the name Set-Cookie so they cant be checked this way
+		if header.Required && !strings.EqualFold(name, "Set-Cookie") && len(r.Response.Header.Values(name)) == 0 {
+			errs = append(errs, fmt.Errorf("missing required header %s", name))
+		}
+	}
+
+	if len(spec.Content) == 0 {
+		return errs
+	}
+	contentType, _, _ := mime.ParseMediaType(r.Response.Header.Get("Content-Type"))
+	media, ok := spec.Content[contentType]
+	if !ok {
+		return append(errs, fmt.Errorf("content type %q is not documented", contentType))
+	}
+	if media.Schema == nil || !strings.HasSuffix(contentType, "json") {
+		return errs
+	}
+	var body any
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		return append(errs, fmt.Errorf("invalid json body: %w", err))
+	}
+	if err := r.validate(body, "/paths", r.Path, strings.ToLower(r.Method), "responses", code, "content", contentType, "schema"); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// validate validates a value against the schema found at the json pointer made from tokens
+// (the entire spec is used as the document so that $refs to components resolve)
+func (r *Result[Resp]) validate(value any, tokens ...string) error {
+	doc, err := json.Marshal(r.Spec)
+	if err != nil {
+		return err
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	if err := compiler.AddResource("openapi.json", bytes.NewReader(doc)); err != nil {
+		return err
+	}
+	pointer := tokens[0]
+	for _, token := range tokens[1:] {
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+		pointer += "/" + escapeFragment(token)
+	}
+	schema, err := compiler.Compile("openapi.json#" + pointer)
+	if err != nil {
+		return err
+	}
+	return schema.Validate(value)
+}
+
+// escapeFragment percent encodes the characters of a json pointer token that arent allowed in a url fragment
+func escapeFragment(token string) string {
+	escaped := ""
+	for _, c := range []byte(token) {
+		if c > 127 || strings.IndexByte(" \"#%<>[\\]^`{|}", c) >= 0 {
+			escaped += fmt.Sprintf("%%%02X", c)
+		} else {
+			escaped += string(c)
+		}
+	}
+	return escaped
+}
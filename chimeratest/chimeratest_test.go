@@ -0,0 +1,120 @@
+package chimeratest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/matt1484/chimera/chimeratest"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestBody struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type TestRequestParams struct {
+	ID     int      `param:"id,in=path"`
+	Tags   []string `param:"tags,in=query"`
+	Trace  string   `param:"x-trace,in=header"`
+	Tenant string   `param:"tenant,in=cookie"`
+}
+
+type TestResponseParams struct {
+	Trace string `param:"x-trace,in=header,required"`
+}
+
+// failures records the failures reported to it instead of failing the test
+type failures []string
+
+func (f *failures) Helper() {}
+
+func (f *failures) Errorf(format string, args ...any) {
+	*f = append(*f, fmt.Sprintf(format, args...))
+}
+
+func (f *failures) Fatalf(format string, args ...any) {
+	*f = append(*f, fmt.Sprintf(format, args...))
+}
+
+func TestCall(t *testing.T) {
+	api := chimera.NewAPI()
+	chimera.Post(api, "/items/{id}", func(req *chimera.JSON[TestBody, TestRequestParams]) (*chimera.JSON[TestBody, TestResponseParams], error) {
+		return &chimera.JSON[TestBody, TestResponseParams]{
+			Body: TestBody{
+				Name:  req.Params.Tenant + ":" + req.Body.Name,
+				Count: req.Params.ID + len(req.Params.Tags),
+			},
+			Params: TestResponseParams{
+				Trace: req.Params.Trace,
+			},
+		}, nil
+	})
+
+	result := chimeratest.Call[chimera.JSON[TestBody, TestResponseParams]](t, api, http.MethodPost, "/items/{id}", &chimera.JSON[TestBody, TestRequestParams]{
+		Body: TestBody{
+			Name: "item",
+		},
+		Params: TestRequestParams{
+			ID:     40,
+			Tags:   []string{"a", "b"},
+			Trace:  "trace-id",
+			Tenant: "acme",
+		},
+	})
+	result.AssertStatus(201).
+		AssertHeader("x-trace", "trace-id").
+		AssertHeader("Content-Type", "application/json").
+		AssertNoError().
+		AssertConformsToSpec()
+	assert.Equal(t, result.Value.Body, TestBody{Name: "acme:item", Count: 42})
+	assert.Equal(t, result.Value.Params.Trace, "trace-id")
+	assert.NotNil(t, result.Operation())
+
+	chimera.Get(api, "/teapot", func(*chimera.EmptyRequest) (*chimera.Response, error) {
+		return &chimera.Response{
+			StatusCode: 418,
+		}, nil
+	})
+	mock := &failures{}
+	chimeratest.Call[chimera.Response](mock, api, http.MethodGet, "/teapot", nil).
+		AssertStatus(418).
+		AssertConformsToSpec()
+	assert.Equal(t, failures{"chimeratest: GET /teapot does not conform to spec: status code 418 is not documented"}, *mock)
+}
+
+type TestEscapedParams struct {
+	Name string `param:"name,in=path"`
+}
+
+func TestCallEscapedPathParams(t *testing.T) {
+	api := chimera.NewAPI()
+	chimera.Get(api, "/files/{name}/meta", func(req *chimera.NoBodyRequest[TestEscapedParams]) (*chimera.JSONResponse[string, chimera.Nil], error) {
+		return &chimera.JSONResponse[string, chimera.Nil]{Body: req.Params.Name}, nil
+	})
+	for _, name := range []string{"a/b", "100%", "what?", "a b", "plain"} {
+		result := chimeratest.Call[chimera.JSONResponse[string, chimera.Nil]](t, api, http.MethodGet, "/files/{name}/meta",
+			&chimera.NoBodyRequest[TestEscapedParams]{Params: TestEscapedParams{Name: name}},
+		)
+		result.AssertStatus(http.StatusOK).AssertNoError()
+		assert.Equal(t, name, result.Value.Body)
+	}
+}
+
+type TestSealedParams struct {
+	Session string `param:"session,in=cookie,signed"`
+}
+
+func TestCallSealedCookies(t *testing.T) {
+	api := chimera.NewAPI(chimera.WithCookieKeys(chimera.NewCookieKeyRing([]byte("0123456789abcdef0123456789abcdef"))))
+	chimera.Get(api, "/echo", func(req *chimera.NoBodyRequest[TestSealedParams]) (*chimera.NoBodyResponse[TestSealedParams], error) {
+		return &chimera.NoBodyResponse[TestSealedParams]{Params: req.Params}, nil
+	})
+	result := chimeratest.Call[chimera.NoBodyResponse[TestSealedParams]](t, api, http.MethodGet, "/echo", &chimera.NoBodyRequest[TestSealedParams]{
+		Params: TestSealedParams{Session: "user-1"},
+	})
+	result.AssertStatus(200).AssertNoError()
+	assert.Equal(t, "user-1", result.Value.Params.Session)
+}
@@ -0,0 +1,115 @@
+package chimera
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// statusCodeReader is implemented by responses that can only be read for certain status codes
+// (i.e. OneOfResponse)
+type statusCodeReader interface {
+	readsStatusCode(statusCode int) bool
+}
+
+// Client calls chimera routes using the same request and response types that the routes are defined with
+type Client struct {
+	// BaseURL is the url that all paths are relative to (i.e. http://localhost:8000/api)
+	BaseURL string
+	// HTTPClient is the client used to send requests (defaults to http.DefaultClient)
+	HTTPClient *http.Client
+	// Header contains headers that are added to every request (i.e. Authorization)
+	// unless the request sets them itself
+	Header http.Header
+	// CookieKeys signs/encrypts the cookies of requests and verifies/decrypts the cookies of responses
+	// (see CookieKeyRing), it is usually the same key ring as the API's
+	CookieKeys *CookieKeyRing
+}
+
+// NewClient returns a new Client for an API served at baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		HTTPClient: http.DefaultClient,
+		Header:     make(http.Header),
+	}
+}
+
+// newRequest creates an http.Request for a path template relative to the client's BaseURL
+func (c *Client) newRequest(ctx context.Context, method, path string) (*http.Request, error) {
+	if c.CookieKeys != nil {
+		ctx = ContextWithCookieKeys(ctx, c.CookieKeys)
+	}
+	template, query, _ := strings.Cut(path, "?")
+	u, err := url.Parse(strings.TrimSuffix(c.BaseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	// the path is kept as a template so that path params can be written into it
+	u.Path += template
+	u.RawPath = ""
+	if query != "" {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += query
+	}
+	request, err := http.NewRequestWithContext(ctx, method, u.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	request.URL = u
+	return request, nil
+}
+
+// Do writes req to an http.Request (params are written to the path template, query, headers and cookies),
+// sends it to the route at path (i.e. /route/{param}) and reads the response into Resp.
+// Responses with a status code >= 400 are returned as an APIError unless Resp is a OneOfResponse
+// with a field for that status code.
+func Do[Resp any, RespPtr ResponseReaderPtr[Resp]](ctx context.Context, client *Client, method, path string, req RequestWriter) (*Resp, error) {
+	request, err := client.newRequest(ctx, method, path)
+	if err != nil {
+		return nil, err
+	}
+	if req != nil {
+		if err := req.WriteRequest(request); err != nil {
+			return nil, err
+		}
+	}
+	// headers written by req take precedence over the client's headers
+	for name, values := range client.Header {
+		if _, ok := request.Header[http.CanonicalHeaderKey(name)]; !ok {
+			request.Header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	value := RespPtr(new(Resp))
+	if response.StatusCode >= 400 {
+		reader, ok := any(value).(statusCodeReader)
+		if !ok || !reader.readsStatusCode(response.StatusCode) {
+			body, err := readResponseBody(response)
+			if err != nil {
+				return nil, err
+			}
+			return nil, APIError{
+				StatusCode: response.StatusCode,
+				Body:       body,
+				Header:     response.Header,
+			}
+		}
+	}
+	if err := value.ReadResponse(response); err != nil {
+		return nil, err
+	}
+	return (*Resp)(value), nil
+}
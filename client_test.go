@@ -0,0 +1,115 @@
+package chimera_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestClientParams struct {
+	ID     int    `param:"id,in=path"`
+	Filter string `param:"filter,in=query"`
+	Token  string `param:"X-Token,in=header"`
+}
+
+type TestClientBody struct {
+	Name string `json:"name"`
+}
+
+type TestClientResponseParams struct {
+	Echo string `param:"X-Echo,in=header"`
+}
+
+type TestClientOneOf struct {
+	Found    *chimera.JSONResponse[TestClientBody, chimera.Nil] `response:"statusCode=200"`
+	NotFound *chimera.NoBodyResponse[chimera.Nil]               `response:"statusCode=404"`
+}
+
+func TestClient(t *testing.T) {
+	api := chimera.NewAPI()
+	chimera.Post(api, "/items/{id}", func(req *chimera.JSON[TestClientBody, TestClientParams]) (*chimera.JSON[TestClientBody, TestClientResponseParams], error) {
+		return &chimera.JSON[TestClientBody, TestClientResponseParams]{
+			Body:   TestClientBody{Name: req.Body.Name + req.Params.Filter},
+			Params: TestClientResponseParams{Echo: req.Params.Token},
+		}, nil
+	})
+	chimera.Get(api, "/items/{id}", func(req *chimera.NoBodyRequest[TestClientParams]) (*chimera.OneOfResponse[TestClientOneOf], error) {
+		if req.Params.ID != 1 {
+			return &chimera.OneOfResponse[TestClientOneOf]{Response: TestClientOneOf{NotFound: &chimera.NoBodyResponse[chimera.Nil]{}}}, nil
+		}
+		return &chimera.OneOfResponse[TestClientOneOf]{Response: TestClientOneOf{Found: &chimera.JSONResponse[TestClientBody, chimera.Nil]{Body: TestClientBody{Name: "one"}}}}, nil
+	})
+	chimera.Delete(api, "/items/{id}", func(req *chimera.NoBodyRequest[TestClientParams]) (*chimera.EmptyResponse, error) {
+		return nil, chimera.APIError{StatusCode: http.StatusForbidden, Body: []byte("forbidden")}
+	})
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client := chimera.NewClient(server.URL)
+	client.Header.Set("X-Token", "secret")
+
+	created, err := chimera.Do[chimera.JSON[TestClientBody, TestClientResponseParams]](
+		context.Background(), client, http.MethodPost, "/items/{id}",
+		&chimera.JSON[TestClientBody, TestClientParams]{
+			Body:   TestClientBody{Name: "name"},
+			Params: TestClientParams{ID: 1, Filter: "-filter", Token: "token"},
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "name-filter", created.Body.Name)
+	assert.Equal(t, "token", created.Params.Echo)
+
+	found, err := chimera.Do[chimera.OneOfResponse[TestClientOneOf]](
+		context.Background(), client, http.MethodGet, "/items/{id}",
+		&chimera.NoBodyRequest[TestClientParams]{Params: TestClientParams{ID: 1}},
+	)
+	assert.NoError(t, err)
+	assert.NotNil(t, found.Response.Found)
+	assert.Nil(t, found.Response.NotFound)
+	assert.Equal(t, "one", found.Response.Found.Body.Name)
+
+	missing, err := chimera.Do[chimera.OneOfResponse[TestClientOneOf]](
+		context.Background(), client, http.MethodGet, "/items/{id}",
+		&chimera.NoBodyRequest[TestClientParams]{Params: TestClientParams{ID: 2}},
+	)
+	assert.NoError(t, err)
+	assert.Nil(t, missing.Response.Found)
+	assert.NotNil(t, missing.Response.NotFound)
+
+	_, err = chimera.Do[chimera.EmptyResponse](
+		context.Background(), client, http.MethodDelete, "/items/{id}",
+		&chimera.NoBodyRequest[TestClientParams]{Params: TestClientParams{ID: 1}},
+	)
+	apiErr, ok := err.(chimera.APIError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+	assert.Equal(t, []byte("forbidden"), apiErr.Body)
+}
+
+type TestEscapedPathParams struct {
+	Name string `param:"name,in=path"`
+}
+
+func TestClientEscapedPathParams(t *testing.T) {
+	api := chimera.NewAPI()
+	chimera.Get(api, "/files/{name}/meta", func(req *chimera.NoBodyRequest[TestEscapedPathParams]) (*chimera.JSONResponse[string, chimera.Nil], error) {
+		return &chimera.JSONResponse[string, chimera.Nil]{Body: req.Params.Name}, nil
+	})
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client := chimera.NewClient(server.URL)
+
+	for _, name := range []string{"a/b", "100%", "what?", "a b", "plain"} {
+		resp, err := chimera.Do[chimera.JSONResponse[string, chimera.Nil]](
+			context.Background(), client, http.MethodGet, "/files/{name}/meta",
+			&chimera.NoBodyRequest[TestEscapedPathParams]{Params: TestEscapedPathParams{Name: name}},
+		)
+		assert.NoError(t, err, name)
+		if assert.NotNil(t, resp, name) {
+			assert.Equal(t, name, resp.Body)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Command chimera-spec shows how to generate the OpenAPI spec of a chimera API without opening a socket.
+// Copy it into a project (i.e. cmd/<name>-spec/main.go), replace newAPI with the function that registers
+// the project's routes and run it in CI:
+//
+//	go run ./cmd/<name>-spec -o openapi.yaml
+//	git diff --exit-code openapi.yaml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/matt1484/chimera"
+)
+
+// newAPI registers the routes of the API, in a real project this is the same function used by main
+func newAPI() *chimera.API {
+	api := chimera.NewAPI()
+	chimera.Get(api, "/health", func(*chimera.EmptyRequest) (*chimera.NoBodyResponse[chimera.Nil], error) {
+		return &chimera.NoBodyResponse[chimera.Nil]{}, nil
+	})
+	return api
+}
+
+func main() {
+	output := flag.String("o", "", "file to write the spec to (format is based on the extension), defaults to stdout")
+	format := flag.String("format", "json", "format to use when writing to stdout (json or yaml)")
+	flag.Parse()
+
+	api := newAPI()
+	var err error
+	if *output == "" {
+		err = api.WriteSpec(os.Stdout, chimera.SpecFormat(*format))
+	} else {
+		err = api.WriteSpecFile(*output)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
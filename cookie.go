@@ -1,8 +1,13 @@
 package chimera
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var (
@@ -20,36 +25,187 @@ type CookieParamMarshaler interface {
 	MarshalCookieParam(ParamStructTag) (http.Cookie, error)
 }
 
-// unmarshalCookieParam converts a cookie to a value using the options in tag
-func unmarshalCookieParam(param http.Cookie, tag *ParamStructTag, addr reflect.Value) error {
+// SameSite is the SameSite attribute of a response cookie (set using sameSite=strict|lax|none in a param tag)
+type SameSite http.SameSite
+
+// UnmarshalTagOption is used to unmarshal a SameSite found in a struct tag
+func (s SameSite) UnmarshalTagOption(field reflect.StructField, value string) (reflect.Value, error) {
+	switch strings.ToLower(value) {
+	case "", "default":
+		return reflect.ValueOf(SameSite(http.SameSiteDefaultMode)), nil
+	case "lax":
+		return reflect.ValueOf(SameSite(http.SameSiteLaxMode)), nil
+	case "strict":
+		return reflect.ValueOf(SameSite(http.SameSiteStrictMode)), nil
+	case "none":
+		return reflect.ValueOf(SameSite(http.SameSiteNoneMode)), nil
+	}
+	return reflect.ValueOf(SameSite(0)), errors.New("invalid param 'sameSite': " + value)
+}
+
+// String returns the value of the SameSite attribute
+func (s SameSite) String() string {
+	switch http.SameSite(s) {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
+
+// applyCookieAttributes sets the attributes from the tag on a response cookie (unless they were already set)
+func (p *ParamStructTag) applyCookieAttributes(cookie *http.Cookie) {
+	cookie.HttpOnly = cookie.HttpOnly || p.HTTPOnly
+	cookie.Secure = cookie.Secure || p.Secure
+	if cookie.SameSite == 0 {
+		cookie.SameSite = http.SameSite(p.SameSite)
+	}
+	if cookie.Path == "" {
+		cookie.Path = p.Path
+	}
+	if cookie.Domain == "" {
+		cookie.Domain = p.Domain
+	}
+	if cookie.MaxAge == 0 {
+		cookie.MaxAge = p.MaxAge
+	}
+	if cookie.Expires.IsZero() && p.expires != 0 {
+		cookie.Expires = time.Now().Add(p.expires)
+	}
+}
+
+// cookieDescription describes a response cookie and its attributes (used in the description of the Set-Cookie header)
+func (p *ParamStructTag) cookieDescription() string {
+	description := "`" + p.Name + "`"
+	if p.Description != "" {
+		description += ": " + p.Description
+	}
+	attributes := make([]string, 0)
+	if p.HTTPOnly {
+		attributes = append(attributes, "HttpOnly")
+	}
+	if p.Secure {
+		attributes = append(attributes, "Secure")
+	}
+	if p.SameSite.String() != "" {
+		attributes = append(attributes, "SameSite="+p.SameSite.String())
+	}
+	if p.Path != "" {
+		attributes = append(attributes, "Path="+p.Path)
+	}
+	if p.Domain != "" {
+		attributes = append(attributes, "Domain="+p.Domain)
+	}
+	if p.MaxAge != 0 {
+		attributes = append(attributes, "Max-Age="+strconv.Itoa(p.MaxAge))
+	}
+	if p.expires != 0 {
+		attributes = append(attributes, "Expires in "+p.expires.String())
+	}
+	if p.Encrypted {
+		attributes = append(attributes, "encrypted")
+	} else if p.Signed {
+		attributes = append(attributes, "signed")
+	}
+	if len(attributes) > 0 {
+		description += " (" + strings.Join(attributes, "; ") + ")"
+	}
+	return description
+}
+
+// unmarshalCookieParam converts a cookie to a value using the options in tag (verifying/decrypting it using keys)
+func unmarshalCookieParam(param http.Cookie, tag *ParamStructTag, addr reflect.Value, keys *CookieKeyRing) error {
 	addr = fixPointer(addr)
+	if err := tag.openCookie(keys, &param); err != nil {
+		return err
+	}
 	if tag.schemaType == interfaceType {
 		return addr.Interface().(CookieParamUnmarshaler).UnmarshalCookieParam(param, *tag)
 	}
 	return unmarshalStringParam(param.Value, tag, addr)
 }
 
-// unmarshalCookieParam converts a value to a http.Cookie using the options in tag
-func marshalCookieParam(tag *ParamStructTag, addr reflect.Value) (http.Cookie, error) {
+// unmarshalResponseCookieParam is the inverse of marshalCookieParam and converts a response cookie to a value
+// (verifying/decrypting it using keys)
+func unmarshalResponseCookieParam(param http.Cookie, tag *ParamStructTag, addr reflect.Value, keys *CookieKeyRing) error {
+	addr = fixPointer(addr)
+	if err := tag.openCookie(keys, &param); err != nil {
+		return err
+	}
+	if u, ok := addr.Interface().(CookieParamUnmarshaler); ok {
+		return u.UnmarshalCookieParam(param, *tag)
+	}
+	if tag.schemaType == interfaceType {
+		return fmt.Errorf("chimera: cookie parameter %s does not implement CookieParamUnmarshaler", tag.Name)
+	}
+	if tag.schemaType == sliceType {
+		// marshalCookieParam always writes slices as comma separated values
+		t := *tag
+		t.prefix = ""
+		t.delim = ","
+		tag = &t
+	}
+	return unmarshalStringParam(param.Value, tag, addr)
+}
+
+// marshalRequestCookieParam is the inverse of unmarshalCookieParam and converts a value to a request cookie
+// (signing/encrypting it using keys)
+func marshalRequestCookieParam(tag *ParamStructTag, addr reflect.Value, keys *CookieKeyRing) (http.Cookie, error) {
+	cookie := http.Cookie{Name: tag.Name}
+	var err error
+	switch tag.schemaType {
+	case interfaceType:
+		m, ok := addr.Interface().(CookieParamMarshaler)
+		if !ok {
+			return http.Cookie{}, fmt.Errorf("chimera: cookie parameter %s does not implement CookieParamMarshaler", tag.Name)
+		}
+		cookie, err = m.MarshalCookieParam(*tag)
+	case encodedType:
+		cookie.Value, err = marshalEncodedParam(tag, addr)
+	default:
+		cookie.Value = marshalStringParam(tag, addr)
+	}
+	if err != nil {
+		return http.Cookie{}, err
+	}
+	if err := tag.sealCookie(keys, &cookie); err != nil {
+		return http.Cookie{}, err
+	}
+	return cookie, nil
+}
+
+// marshalCookieParam converts a value to a http.Cookie using the options in tag (signing/encrypting it using keys)
+func marshalCookieParam(tag *ParamStructTag, addr reflect.Value, keys *CookieKeyRing) (http.Cookie, error) {
 	addr = fixPointer(addr)
+	cookie := http.Cookie{Name: tag.Name}
 	switch tag.schemaType {
 	case interfaceType:
-		return addr.Interface().(CookieParamMarshaler).MarshalCookieParam(*tag)
+		var err error
+		cookie, err = addr.Interface().(CookieParamMarshaler).MarshalCookieParam(*tag)
+		if err != nil {
+			return cookie, err
+		}
 	case primitiveType:
-		return http.Cookie{
-			Name:  tag.Name,
-			Value: marshalPrimitiveToString(addr),
-		}, nil
+		cookie.Value = encodeParamString(addr, tag.Layout)
 	case sliceType:
-		return http.Cookie{
-			Name:  tag.Name,
-			Value: marshalSliceToString(addr),
-		}, nil
+		cookie.Value = marshalSliceToString(addr, tag)
 	case structType:
-		return http.Cookie{
-			Name:  tag.Name,
-			Value: marshalStructToString(addr, tag),
-		}, nil
+		cookie.Value = marshalStructToString(addr, tag)
+	case encodedType:
+		var err error
+		cookie.Value, err = marshalEncodedParam(tag, addr)
+		if err != nil {
+			return http.Cookie{}, err
+		}
+	default:
+		return http.Cookie{}, nil
+	}
+	tag.applyCookieAttributes(&cookie)
+	if err := tag.sealCookie(keys, &cookie); err != nil {
+		return http.Cookie{}, err
 	}
-	return http.Cookie{}, nil
+	return cookie, nil
 }
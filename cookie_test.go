@@ -1,6 +1,15 @@
 package chimera_test
 
-import "net/http"
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
 
 type TestPrimitiveCookieParams struct {
 	FormStr  string  `param:"formstr,in=cookie,style=form"`
@@ -150,3 +159,52 @@ var (
 		FormExplodeF64:  []float64{-123.45},
 	}
 )
+
+type TestCookieAttributeParams struct {
+	Session string `param:"session,in=cookie,required,httpOnly,secure,sameSite=strict,path=/app,domain=example.com,maxAge=3600"`
+	Theme   string `param:"theme,in=cookie,description='ui theme',sameSite=lax,expires=24h"`
+	Plain   string `param:"plain,in=cookie"`
+}
+
+func TestResponseCookieAttributes(t *testing.T) {
+	api := chimera.NewAPI()
+	route := chimera.Get(api, "/cookies", func(req *chimera.EmptyRequest) (*chimera.NoBodyResponse[TestCookieAttributeParams], error) {
+		return &chimera.NoBodyResponse[TestCookieAttributeParams]{
+			Params: TestCookieAttributeParams{Session: "abc", Theme: "dark", Plain: "value"},
+		}, nil
+	})
+	server := httptest.NewServer(api)
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/cookies")
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	cookies := make(map[string]string)
+	for _, header := range resp.Header.Values("Set-Cookie") {
+		name, _, _ := strings.Cut(header, "=")
+		cookies[name] = header
+	}
+	assert.Len(t, cookies, 3)
+	assert.Equal(t, "session=abc; Path=/app; Domain=example.com; Max-Age=3600; HttpOnly; Secure; SameSite=Strict", cookies["session"])
+	assert.Equal(t, "plain=value", cookies["plain"])
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "theme" {
+			assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+			assert.WithinDuration(t, time.Now().Add(24*time.Hour), cookie.Expires, time.Minute)
+		}
+	}
+
+	headers := route.OpenAPIOperationSpec().Responses["200"].Headers
+	assert.Len(t, headers, 1)
+	assert.True(t, headers["Set-Cookie"].Required)
+	assert.Equal(t, "`session` (HttpOnly; Secure; SameSite=Strict; Path=/app; Domain=example.com; Max-Age=3600)\n`theme`: ui theme (SameSite=Lax; Expires in 24h0m0s)\n`plain`", headers["Set-Cookie"].Description)
+
+	type invalidExpires struct {
+		Cookie string `param:"cookie,in=cookie,expires=tomorrow"`
+	}
+	assert.Panics(t, func() {
+		chimera.Get(api, "/invalid", func(req *chimera.EmptyRequest) (*chimera.NoBodyResponse[invalidExpires], error) {
+			return nil, nil
+		})
+	})
+}
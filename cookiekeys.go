@@ -0,0 +1,234 @@
+package chimera
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	minCookieKeyLength = 16
+)
+
+var (
+	errCookieKeysMissing = errors.New("chimera: signed/encrypted cookies require cookie keys (see WithCookieKeys)")
+)
+
+// cookieKeysContextKey is the context key of the CookieKeyRing used to read the cookies of a request
+type cookieKeysContextKey struct{}
+
+// CookieKeyRing holds the keys used by signed and encrypted cookie params. The first key signs/encrypts
+// new cookies and every key is tried when verifying/decrypting so keys can be rotated without invalidating
+// cookies that were already sent. Signed/encrypted values have no timestamp so they never expire on their own,
+// a cookie stays valid for as long as its key is in the ring (maxAge/expires only tell the browser when to drop it)
+// so values that must expire should include their own expiry (or keys should be removed with SetKeys).
+type CookieKeyRing struct {
+	lock sync.RWMutex
+	keys [][]byte
+}
+
+// NewCookieKeyRing creates a key ring where current is used for new cookies and previous keys are only used
+// to read existing cookies. Keys must be at least 16 bytes (32 random bytes are recommended).
+func NewCookieKeyRing(current []byte, previous ...[]byte) *CookieKeyRing {
+	ring := &CookieKeyRing{}
+	ring.SetKeys(append([][]byte{current}, previous...)...)
+	return ring
+}
+
+// SetKeys replaces the keys of the ring, the first key is used for new cookies
+func (k *CookieKeyRing) SetKeys(keys ...[]byte) {
+	if len(keys) == 0 {
+		panic("chimera: a cookie key ring requires at least one key")
+	}
+	for _, key := range keys {
+		if len(key) < minCookieKeyLength {
+			panic(fmt.Sprintf("chimera: cookie keys must be at least %d bytes", minCookieKeyLength))
+		}
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.keys = keys
+}
+
+// Rotate makes key the current key, the previous keys can still read existing cookies until they are removed
+// (see SetKeys)
+func (k *CookieKeyRing) Rotate(key []byte) {
+	if len(key) < minCookieKeyLength {
+		panic(fmt.Sprintf("chimera: cookie keys must be at least %d bytes", minCookieKeyLength))
+	}
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.keys = append([][]byte{key}, k.keys...)
+}
+
+// Keys returns the keys of the ring (the current key first)
+func (k *CookieKeyRing) Keys() [][]byte {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	return append([][]byte{}, k.keys...)
+}
+
+// WithCookieKeys sets the key ring used by signed and encrypted cookie params, groups and mounted APIs
+// without their own key ring use their parent's
+func WithCookieKeys(ring *CookieKeyRing) APIOption {
+	return func(a *API) {
+		a.cookieKeys = ring
+	}
+}
+
+// cookieKeyRing gets the nearest CookieKeyRing up the parent chain of an API
+func (a *API) cookieKeyRing() *CookieKeyRing {
+	for api := a; api != nil; api = api.parent {
+		if api.cookieKeys != nil {
+			return api.cookieKeys
+		}
+	}
+	return nil
+}
+
+// CookieKeys gets the key ring used by the signed and encrypted cookie params of an API (see WithCookieKeys)
+func (a *API) CookieKeys() *CookieKeyRing {
+	return a.cookieKeyRing()
+}
+
+// ContextWithCookieKeys returns a context with a key ring used to write the signed and encrypted cookies of a request
+// (see MarshalRequestParams) and read the ones of its response (see UnmarshalResponseParams)
+func ContextWithCookieKeys(ctx context.Context, keys *CookieKeyRing) context.Context {
+	return context.WithValue(ctx, cookieKeysContextKey{}, keys)
+}
+
+// cookieKeysFromContext gets the CookieKeyRing added to a request's context by its route
+func cookieKeysFromContext(ctx context.Context) *CookieKeyRing {
+	keys, _ := ctx.Value(cookieKeysContextKey{}).(*CookieKeyRing)
+	return keys
+}
+
+// sign returns the value with a signature of the cookie name and value
+func (k *CookieKeyRing) sign(name, value string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(cookieMAC(k.Keys()[0], name, payload))
+}
+
+// verify checks the signature of a signed cookie against every key and returns its value
+func (k *CookieKeyRing) verify(name, value string) (string, bool) {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return "", false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return "", false
+	}
+	for _, key := range k.Keys() {
+		if hmac.Equal(mac, cookieMAC(key, name, payload)) {
+			decoded, err := base64.RawURLEncoding.DecodeString(payload)
+			return string(decoded), err == nil
+		}
+	}
+	return "", false
+}
+
+// encrypt encrypts the value of a cookie (the cookie name is authenticated as well)
+func (k *CookieKeyRing) encrypt(name, value string) (string, error) {
+	gcm, err := cookieCipher(k.Keys()[0])
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt decrypts an encrypted cookie using every key
+func (k *CookieKeyRing) decrypt(name, value string) (string, bool) {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", false
+	}
+	for _, key := range k.Keys() {
+		gcm, err := cookieCipher(key)
+		if err != nil || len(sealed) < gcm.NonceSize() {
+			continue
+		}
+		opened, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(name))
+		if err == nil {
+			return string(opened), true
+		}
+	}
+	return "", false
+}
+
+// cookieMAC signs a cookie's name and payload
+func cookieMAC(key []byte, name, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name + "=" + payload))
+	return mac.Sum(nil)
+}
+
+// cookieCipher creates an AES-GCM cipher from a key of any length (separate from the one used for signing)
+func cookieCipher(key []byte) (cipher.AEAD, error) {
+	derived := sha256.Sum256(append([]byte("chimera cookie encryption:"), key...))
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealCookie signs and/or encrypts a response cookie based on its tag
+func (p *ParamStructTag) sealCookie(keys *CookieKeyRing, cookie *http.Cookie) error {
+	if !p.Signed && !p.Encrypted {
+		return nil
+	}
+	if keys == nil {
+		return errCookieKeysMissing
+	}
+	if p.Encrypted {
+		encrypted, err := keys.encrypt(cookie.Name, cookie.Value)
+		if err != nil {
+			return err
+		}
+		cookie.Value = encrypted
+	} else {
+		cookie.Value = keys.sign(cookie.Name, cookie.Value)
+	}
+	return nil
+}
+
+// openCookie verifies and/or decrypts a request cookie based on its tag, cookies that were tampered with
+// are rejected with a 401
+func (p *ParamStructTag) openCookie(keys *CookieKeyRing, cookie *http.Cookie) error {
+	if !p.Signed && !p.Encrypted {
+		return nil
+	}
+	if keys == nil {
+		return errCookieKeysMissing
+	}
+	var value string
+	var ok bool
+	if p.Encrypted {
+		value, ok = keys.decrypt(cookie.Name, cookie.Value)
+	} else {
+		value, ok = keys.verify(cookie.Name, cookie.Value)
+	}
+	if !ok {
+		return APIError{
+			StatusCode: http.StatusUnauthorized,
+			Body:       []byte(fmt.Sprintf("cookie parameter %s is invalid", cookie.Name)),
+		}
+	}
+	cookie.Value = value
+	return nil
+}
@@ -0,0 +1,150 @@
+package chimera_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestSealedCookieParams struct {
+	Session string `param:"session,in=cookie,signed"`
+	CSRF    int    `param:"csrf,in=cookie,encrypted"`
+}
+
+func TestSignedAndEncryptedCookies(t *testing.T) {
+	oldKey := []byte("0123456789abcdef0123456789abcdef")
+	newKey := []byte("fedcba9876543210fedcba9876543210")
+	ring := chimera.NewCookieKeyRing(oldKey)
+	api := chimera.NewAPI(chimera.WithCookieKeys(ring))
+	group := api.Group("/group")
+	chimera.Get(group, "/login", func(req *chimera.EmptyRequest) (*chimera.NoBodyResponse[TestSealedCookieParams], error) {
+		return &chimera.NoBodyResponse[TestSealedCookieParams]{
+			Params: TestSealedCookieParams{Session: "user-1", CSRF: 12345},
+		}, nil
+	})
+	chimera.Get(group, "/me", func(req *chimera.NoBodyRequest[TestSealedCookieParams]) (*chimera.PlainTextResponse[chimera.Nil], error) {
+		return &chimera.PlainTextResponse[chimera.Nil]{Body: req.Params.Session + ":" + strings.Repeat("x", req.Params.CSRF%10)}, nil
+	})
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	login := func() map[string]*http.Cookie {
+		resp, err := http.Get(server.URL + "/group/login")
+		assert.NoError(t, err)
+		resp.Body.Close()
+		cookies := make(map[string]*http.Cookie)
+		for _, c := range resp.Cookies() {
+			cookies[c.Name] = c
+		}
+		return cookies
+	}
+	me := func(session, csrf string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/group/me", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: session})
+		req.AddCookie(&http.Cookie{Name: "csrf", Value: csrf})
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body := make([]byte, 64)
+		n, _ := resp.Body.Read(body)
+		return resp.StatusCode, string(body[:n])
+	}
+
+	cookies := login()
+	assert.Contains(t, cookies["session"].Value, ".")
+	assert.NotContains(t, cookies["csrf"].Value, "12345")
+	code, body := me(cookies["session"].Value, cookies["csrf"].Value)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "user-1:xxxxx", body)
+
+	// tampering is rejected
+	code, _ = me("dXNlci0y"+cookies["session"].Value[strings.Index(cookies["session"].Value, "."):], cookies["csrf"].Value)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = me(cookies["session"].Value, cookies["csrf"].Value[:len(cookies["csrf"].Value)-2]+"AA")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = me("user-1", cookies["csrf"].Value)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// rotated keys can still read old cookies until they are removed
+	ring.Rotate(newKey)
+	code, _ = me(cookies["session"].Value, cookies["csrf"].Value)
+	assert.Equal(t, http.StatusOK, code)
+	rotated := login()
+	assert.NotEqual(t, cookies["session"].Value, rotated["session"].Value)
+	ring.SetKeys(newKey)
+	code, _ = me(cookies["session"].Value, cookies["csrf"].Value)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = me(rotated["session"].Value, rotated["csrf"].Value)
+	assert.Equal(t, http.StatusOK, code)
+
+	// keys are required
+	noKeys := chimera.NewAPI()
+	chimera.Get(noKeys, "/login", func(req *chimera.EmptyRequest) (*chimera.NoBodyResponse[TestSealedCookieParams], error) {
+		return &chimera.NoBodyResponse[TestSealedCookieParams]{}, nil
+	})
+	w := httptest.NewRecorder()
+	noKeys.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Panics(t, func() {
+		chimera.NewCookieKeyRing([]byte("short"))
+	})
+}
+
+type TestSealedClientParams struct {
+	Session  string           `param:"session,in=cookie,signed"`
+	Settings TestTraceContext `param:"settings,in=cookie,encrypted,encoding=base64json"`
+}
+
+func TestClientSealedCookies(t *testing.T) {
+	ring := chimera.NewCookieKeyRing([]byte("0123456789abcdef0123456789abcdef"))
+	api := chimera.NewAPI(chimera.WithCookieKeys(ring))
+	chimera.Get(api, "/echo", func(req *chimera.NoBodyRequest[TestSealedClientParams]) (*chimera.NoBodyResponse[TestSealedClientParams], error) {
+		return &chimera.NoBodyResponse[TestSealedClientParams]{Params: req.Params}, nil
+	})
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client := chimera.NewClient(server.URL)
+	client.CookieKeys = ring
+
+	params := TestSealedClientParams{Session: "user-1", Settings: TestTraceContext{TraceID: "abc", Sampled: true}}
+	resp, err := chimera.Do[chimera.NoBodyResponse[TestSealedClientParams]](
+		context.Background(), client, http.MethodGet, "/echo",
+		&chimera.NoBodyRequest[TestSealedClientParams]{Params: params},
+	)
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp) {
+		assert.Equal(t, params, resp.Params)
+	}
+
+	// clients without the keys cant write (or read) sealed cookies
+	client.CookieKeys = nil
+	_, err = chimera.Do[chimera.NoBodyResponse[TestSealedClientParams]](
+		context.Background(), client, http.MethodGet, "/echo",
+		&chimera.NoBodyRequest[TestSealedClientParams]{Params: params},
+	)
+	assert.Error(t, err)
+}
+
+func TestCookieKeyRingRotate(t *testing.T) {
+	ring := chimera.NewCookieKeyRing([]byte("0123456789abcdef0123456789abcdef"))
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ring.Rotate([]byte(strings.Repeat(string(rune('a'+i%26)), 32)))
+		}(i)
+	}
+	wg.Wait()
+	// every rotation is kept
+	assert.Len(t, ring.Keys(), 51)
+	assert.Panics(t, func() {
+		ring.Rotate([]byte("short"))
+	})
+}
@@ -0,0 +1,156 @@
+package chimera
+
+// OperationDefaults are applied to the Operation of every route in an API and its groups/mounted APIs
+// when the spec is built. Values set on a route take precedence over these.
+type OperationDefaults struct {
+	// Tags are added to every operation (before the route's own tags), tags with a description
+	// are also added to the top-level tags of the spec
+	Tags []Tag
+	// Security is used by operations that dont set their own
+	Security []map[string][]string
+	// Servers are used by operations that dont set their own
+	Servers []Server
+	// Deprecated marks every operation as deprecated
+	Deprecated bool
+}
+
+// WithOperationDefaults sets the operation defaults of an API (i.e. a group), nested groups
+// combine their defaults with their parent's
+func (a *API) WithOperationDefaults(defaults OperationDefaults) *API {
+	a.operationDefaults = defaults
+	rebuildAPI(a)
+	return a
+}
+
+// merge combines parent defaults with child defaults (the child takes precedence)
+func (d OperationDefaults) merge(child OperationDefaults) OperationDefaults {
+	merged := OperationDefaults{
+		Tags:       append(append([]Tag{}, d.Tags...), child.Tags...),
+		Security:   d.Security,
+		Servers:    d.Servers,
+		Deprecated: d.Deprecated || child.Deprecated,
+	}
+	if len(child.Security) > 0 {
+		merged.Security = child.Security
+	}
+	if len(child.Servers) > 0 {
+		merged.Servers = child.Servers
+	}
+	return merged
+}
+
+// apply returns a copy of op with the defaults applied, required security (i.e. from dependencies)
+// is added to every security requirement of the operation
+func (d OperationDefaults) apply(op *Operation, required map[string][]string) *Operation {
+	applied := *op
+	if len(d.Tags) > 0 {
+		seen := make(map[string]struct{})
+		applied.Tags = make([]string, 0, len(d.Tags)+len(op.Tags))
+		for _, tag := range d.Tags {
+			if _, ok := seen[tag.Name]; !ok {
+				seen[tag.Name] = struct{}{}
+				applied.Tags = append(applied.Tags, tag.Name)
+			}
+		}
+		for _, tag := range op.Tags {
+			if _, ok := seen[tag]; !ok {
+				seen[tag] = struct{}{}
+				applied.Tags = append(applied.Tags, tag)
+			}
+		}
+	}
+	if len(applied.Security) == 0 {
+		applied.Security = d.Security
+	}
+	if len(required) > 0 {
+		if len(applied.Security) == 0 {
+			applied.Security = []map[string][]string{required}
+		} else {
+			security := make([]map[string][]string, len(applied.Security))
+			for i, requirement := range applied.Security {
+				security[i] = mergeSecurityRequirements(requirement, required)
+			}
+			applied.Security = security
+		}
+	}
+	if len(applied.Servers) == 0 {
+		applied.Servers = d.Servers
+	}
+	applied.Deprecated = applied.Deprecated || d.Deprecated
+	return &applied
+}
+
+// withOperationDefaults returns a copy of spec (the spec of the API tree) with the operation defaults applied,
+// the operations of the routes are left untouched so they can still be changed through Route
+func (a *API) withOperationDefaults(spec *OpenAPI) *OpenAPI {
+	applied := *spec
+	applied.Tags = append([]Tag{}, spec.Tags...)
+	applied.Paths = make(map[string]Path, len(spec.Paths))
+	for path, obj := range spec.Paths {
+		applied.Paths[path] = obj
+	}
+	a.applyOperationDefaults(&applied, OperationDefaults{})
+	return &applied
+}
+
+// applyOperationDefaults replaces the operations of every route in the API tree with copies that have
+// their defaults applied and adds the described group tags to the top-level tags of spec
+func (a *API) applyOperationDefaults(spec *OpenAPI, parent OperationDefaults) {
+	defaults := parent.merge(a.operationDefaults)
+	for _, tag := range a.operationDefaults.Tags {
+		if tag.Description == "" {
+			continue
+		}
+		found := false
+		for i, existing := range spec.Tags {
+			if existing.Name == tag.Name {
+				found = true
+				if existing.Description == "" {
+					spec.Tags[i].Description = tag.Description
+				}
+				break
+			}
+		}
+		if !found {
+			spec.Tags = append(spec.Tags, tag)
+		}
+	}
+	for _, r := range a.routes {
+		if r.hidden || r.operationSpec == nil {
+			continue
+		}
+		for path, obj := range spec.Paths {
+			for _, op := range []**Operation{&obj.Get, &obj.Put, &obj.Post, &obj.Delete, &obj.Options, &obj.Head, &obj.Patch, &obj.Trace} {
+				if *op == r.operationSpec {
+					*op = defaults.apply(r.operationSpec, r.requiredSecurity)
+				}
+			}
+			spec.Paths[path] = obj
+		}
+	}
+	for _, sub := range a.subAPIs {
+		sub.applyOperationDefaults(spec, defaults)
+	}
+}
+
+// mergeSecurityRequirements combines security requirements into a single requirement (all of them are needed)
+func mergeSecurityRequirements(requirements ...map[string][]string) map[string][]string {
+	merged := make(map[string][]string)
+	for _, requirement := range requirements {
+		for scheme, scopes := range requirement {
+			if _, ok := merged[scheme]; !ok {
+				merged[scheme] = make([]string, 0, len(scopes))
+			}
+			for _, scope := range scopes {
+				found := false
+				for _, s := range merged[scheme] {
+					found = found || s == scope
+				}
+				if !found {
+					merged[scheme] = append(merged[scheme], scope)
+				}
+			}
+		}
+	}
+	return merged
+}
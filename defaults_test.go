@@ -0,0 +1,104 @@
+package chimera_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationDefaults(t *testing.T) {
+	api := chimera.NewAPI()
+	api.OpenAPISpec().Tags = []chimera.Tag{{Name: "billing"}}
+	billing := api.Group("/billing").WithOperationDefaults(chimera.OperationDefaults{
+		Tags:       []chimera.Tag{{Name: "billing", Description: "Billing operations"}},
+		Security:   []map[string][]string{{"bearerAuth": {}}},
+		Servers:    []chimera.Server{{URL: "https://billing.example.com"}},
+		Deprecated: true,
+	})
+	chimera.Get(billing, "/invoices", ListTestOperations)
+	chimera.Get(billing, "/public", ListTestOperations).WithOperation(chimera.Operation{
+		Tags:     []string{"public"},
+		Security: []map[string][]string{{"apiKey": {}}},
+	})
+	legacy := billing.Group("/legacy").WithOperationDefaults(chimera.OperationDefaults{
+		Tags:    []chimera.Tag{{Name: "legacy", Description: "Legacy operations"}},
+		Servers: []chimera.Server{{URL: "https://legacy.example.com"}},
+	})
+	chimera.Get(legacy, "/receipts", ListTestOperations)
+	chimera.Get(api, "/health", ListTestOperations)
+
+	out := bytes.Buffer{}
+	assert.NoError(t, api.WriteSpec(&out, chimera.SpecFormatJSON))
+	spec := chimera.OpenAPI{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &spec))
+	operations := make(map[string]*chimera.Operation)
+	for _, path := range spec.Paths {
+		operations[path.Get.OperationID] = path.Get
+	}
+
+	invoices := operations["getBillingInvoices"]
+	assert.Equal(t, []string{"billing"}, invoices.Tags)
+	assert.Equal(t, []map[string][]string{{"bearerAuth": {}}}, invoices.Security)
+	assert.Equal(t, "https://billing.example.com", invoices.Servers[0].URL)
+	assert.True(t, invoices.Deprecated)
+
+	public := operations["getBillingPublic"]
+	assert.Equal(t, []string{"billing", "public"}, public.Tags)
+	assert.Equal(t, []map[string][]string{{"apiKey": {}}}, public.Security)
+
+	receipts := operations["getBillingLegacyReceipts"]
+	assert.Equal(t, []string{"billing", "legacy"}, receipts.Tags)
+	assert.Equal(t, []map[string][]string{{"bearerAuth": {}}}, receipts.Security)
+	assert.Equal(t, "https://legacy.example.com", receipts.Servers[0].URL)
+	assert.True(t, receipts.Deprecated)
+
+	health := operations["getHealth"]
+	assert.Empty(t, health.Tags)
+	assert.False(t, health.Deprecated)
+
+	assert.Equal(t, []chimera.Tag{
+		{Name: "billing", Description: "Billing operations"},
+		{Name: "legacy", Description: "Legacy operations"},
+	}, spec.Tags)
+
+	// the routes themselves are untouched
+	assert.Empty(t, api.OpenAPISpec().Paths["/health"].Get.Tags)
+}
+
+func TestOperationDefaultsRouteChanges(t *testing.T) {
+	api := chimera.NewAPI()
+	group := api.Group("/group").WithOperationDefaults(chimera.OperationDefaults{
+		Tags: []chimera.Tag{{Name: "group"}},
+	})
+	chimera.Get(group, "/a", ListTestOperations).WithOperation(chimera.Operation{
+		Summary:     "SUMMARY-A",
+		OperationID: "customA",
+	})
+	chimera.Get(api, "/b", ListTestOperations).UsingOperation(chimera.Operation{
+		Summary:     "SUMMARY-B",
+		OperationID: "customB",
+	})
+
+	// changes made to routes after they were added are served with the defaults applied
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	spec := chimera.OpenAPI{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "customA", spec.Paths["/group/a"].Get.OperationID)
+	assert.Equal(t, "SUMMARY-A", spec.Paths["/group/a"].Get.Summary)
+	assert.Equal(t, []string{"group"}, spec.Paths["/group/a"].Get.Tags)
+	assert.Equal(t, "customB", spec.Paths["/b"].Get.OperationID)
+	assert.Equal(t, "SUMMARY-B", spec.Paths["/b"].Get.Summary)
+
+	out := bytes.Buffer{}
+	assert.NoError(t, api.WriteSpec(&out, chimera.SpecFormatJSON))
+	spec = chimera.OpenAPI{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &spec))
+	assert.Equal(t, "customA", spec.Paths["/group/a"].Get.OperationID)
+	assert.Equal(t, "SUMMARY-A", spec.Paths["/group/a"].Get.Summary)
+}
@@ -0,0 +1,298 @@
+package chimera
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/matt1484/spectagular"
+)
+
+var (
+	errorType        = reflect.TypeOf((*error)(nil)).Elem()
+	contextType      = reflect.TypeOf((*context.Context)(nil)).Elem()
+	routeContextType = reflect.TypeOf((*RouteContext)(nil)).Elem()
+	httpRequestType  = reflect.TypeOf(&http.Request{})
+	cleanupType      = reflect.TypeOf(func() {})
+	errCleanupType   = reflect.TypeOf(func(error) {})
+)
+
+// provider is a function that provides a dependency (see API.Provide)
+type provider struct {
+	fn       reflect.Value
+	out      reflect.Type
+	cleanup  int
+	err      int
+	security []map[string][]string
+}
+
+// Provide adds dependency providers to the API (and its groups), fields of a request type tagged with `depends:""`
+// are injected with the value returned by the provider of the field's type. Providers are functions of the form:
+//
+//	func(args...) T
+//	func(args...) (T, error)
+//	func(args...) (T, func(), error) // or func(error) to get the error (if any) returned while handling the request
+//
+// where each arg is a *http.Request, context.Context, RouteContext, a dependency with its own provider
+// or a Params struct (a struct with param tags and no provider) which is parsed like the params of a request. Dependencies are resolved once per request
+// and cleanup functions are called (in reverse order) after the response is written.
+// The params of a provider (and its dependencies) are added to the operation of every route that depends on it.
+// The security requirements provided here are all needed (they are combined into one requirement) and are added to
+// every security requirement of the operation (including the defaults of its groups) when the spec is built, since
+// the provider needs them no matter which requirement is met. Providers must be added before the routes using them
+// (the providers are resolved when a route is added), groups can replace the providers of their parents
+func (a *API) Provide(providerFunc any, security ...map[string][]string) {
+	fn := reflect.ValueOf(providerFunc)
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		panic(fmt.Sprintf("chimera: dependency provider must be a function, got %T", providerFunc))
+	}
+	t := fn.Type()
+	p := provider{
+		fn:       fn,
+		cleanup:  -1,
+		err:      -1,
+		security: security,
+	}
+	if t.IsVariadic() || t.NumOut() < 1 || t.NumOut() > 3 || t.Out(0) == errorType {
+		panic("chimera: invalid dependency provider " + t.String())
+	}
+	p.out = t.Out(0)
+	for i := 1; i < t.NumOut(); i++ {
+		switch {
+		case i == t.NumOut()-1 && t.Out(i) == errorType:
+			p.err = i
+		case i == 1 && (t.Out(i) == cleanupType || t.Out(i) == errCleanupType):
+			p.cleanup = i
+		default:
+			panic("chimera: invalid dependency provider " + t.String())
+		}
+	}
+	switch p.out {
+	case httpRequestType, contextType, routeContextType:
+		panic("chimera: " + p.out.String() + " is always provided")
+	}
+	if a.providers == nil {
+		a.providers = make(map[reflect.Type]*provider)
+	}
+	a.providers[p.out] = &p
+}
+
+// provider gets the nearest provider of a type up the parent chain of an API
+func (a *API) provider(t reflect.Type) *provider {
+	for api := a; api != nil; api = api.parent {
+		if p, ok := api.providers[t]; ok {
+			return p
+		}
+	}
+	return nil
+}
+
+// dependencyField is a field of a request type that is injected with a dependency
+type dependencyField struct {
+	index []int
+	t     reflect.Type
+}
+
+// dependencies describes the dependencies of a request type and their spec
+type dependencies struct {
+	fields     []dependencyField
+	parameters []Parameter
+	security   map[string][]string
+	// providers are the providers found when the route was added (nil for params structs)
+	providers map[reflect.Type]*provider
+}
+
+// newDependencies finds the dependency fields of a request type and makes sure every one of them
+// (and the args of their providers) can be resolved, it returns nil if there are none
+func newDependencies(api *API, t reflect.Type) *dependencies {
+	fields := dependencyFields(t, nil)
+	if len(fields) == 0 {
+		return nil
+	}
+	deps := dependencies{
+		fields:    fields,
+		providers: make(map[reflect.Type]*provider),
+	}
+	seen := make(map[reflect.Type]bool)
+	for _, field := range fields {
+		deps.check(api, field.t, seen)
+	}
+	return &deps
+}
+
+// dependencyFields finds the fields tagged with `depends` in a struct and its (non-pointer) struct fields
+func dependencyFields(t reflect.Type, index []int) []dependencyField {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := make([]dependencyField, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		if isDependencyField(field) {
+			fields = append(fields, dependencyField{index: fieldIndex, t: field.Type})
+		} else if field.Type.Kind() == reflect.Struct {
+			fields = append(fields, dependencyFields(field.Type, fieldIndex)...)
+		}
+	}
+	return fields
+}
+
+// isDependencyField checks if a struct field is tagged with `depends`
+func isDependencyField(field reflect.StructField) bool {
+	tag, ok := field.Tag.Lookup("depends")
+	return ok && tag != "-"
+}
+
+// isParamsStruct checks if a type is a struct with param tags (so it can be parsed from a request)
+func isParamsStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	tags, err := requestParamTagCache.GetOrAdd(t)
+	if err != nil {
+		return false
+	}
+	for _, tag := range tags {
+		if tag.Value.Name != spectagular.EmptyTag && tag.Value.Name != spectagular.SkipTag {
+			return true
+		}
+	}
+	return false
+}
+
+// check makes sure a dependency can be resolved and collects the params and security of its provider,
+// seen is true for dependencies that are being checked (to find cycles) and false once they are done
+func (d *dependencies) check(api *API, t reflect.Type, seen map[reflect.Type]bool) {
+	if checking, ok := seen[t]; ok {
+		if checking {
+			panic("chimera: dependency cycle found for " + t.String())
+		}
+		return
+	}
+	switch t {
+	case httpRequestType, contextType, routeContextType:
+		return
+	}
+	p := api.provider(t)
+	d.providers[t] = p
+	if p == nil {
+		if !isParamsStruct(t) {
+			panic("chimera: no dependency provider for " + t.String())
+		}
+		// params structs without providers are parsed from the request
+		seen[t] = false
+		for _, param := range CacheRequestParamsType(t) {
+			if !hasParameter(d.parameters, param) {
+				d.parameters = append(d.parameters, param)
+			}
+		}
+		return
+	}
+	seen[t] = true
+	for i := 0; i < p.fn.Type().NumIn(); i++ {
+		d.check(api, p.fn.Type().In(i), seen)
+	}
+	seen[t] = false
+	// every provider is needed so their requirements are too
+	if len(p.security) > 0 {
+		d.security = mergeSecurityRequirements(append([]map[string][]string{d.security}, p.security...)...)
+	}
+}
+
+// dependencyResolver resolves the dependencies of a single request
+type dependencyResolver struct {
+	deps     *dependencies
+	request  *http.Request
+	ctx      RouteContext
+	values   map[reflect.Type]reflect.Value
+	cleanups []reflect.Value
+}
+
+// inject resolves the dependencies of a request and sets them on the request object
+func (d *dependencies) inject(resolver *dependencyResolver, request reflect.Value) error {
+	for _, field := range d.fields {
+		value, err := resolver.resolve(field.t)
+		if err != nil {
+			return err
+		}
+		request.FieldByIndex(field.index).Set(value)
+	}
+	return nil
+}
+
+// resolve gets the value of a dependency, calling its provider (and the providers of its args) if needed
+func (r *dependencyResolver) resolve(t reflect.Type) (reflect.Value, error) {
+	if value, ok := r.values[t]; ok {
+		return value, nil
+	}
+	switch t {
+	case httpRequestType:
+		return reflect.ValueOf(r.request), nil
+	case contextType:
+		return reflect.ValueOf(r.request.Context()), nil
+	case routeContextType:
+		return reflect.ValueOf(&r.ctx).Elem(), nil
+	}
+	var value reflect.Value
+	p := r.deps.providers[t]
+	if p == nil {
+		params := reflect.New(t)
+		if err := UnmarshalParams(r.request, params.Interface()); err != nil {
+			return value, err
+		}
+		value = params.Elem()
+	} else {
+		args := make([]reflect.Value, p.fn.Type().NumIn())
+		for i := range args {
+			arg, err := r.resolve(p.fn.Type().In(i))
+			if err != nil {
+				return value, err
+			}
+			args[i] = arg
+		}
+		out := p.fn.Call(args)
+		if p.err > 0 && !out[p.err].IsNil() {
+			return value, out[p.err].Interface().(error)
+		}
+		if p.cleanup > 0 && !out[p.cleanup].IsNil() {
+			r.cleanups = append(r.cleanups, out[p.cleanup])
+		}
+		value = out[0]
+	}
+	if r.values == nil {
+		r.values = make(map[reflect.Type]reflect.Value)
+	}
+	r.values[t] = value
+	return value, nil
+}
+
+// cleanupDependencies calls the cleanup functions of the request's dependencies once the response is written
+// (or a handler/provider panicked, the panic is passed to the cleanups as an error and then continues)
+func (w *httpResponseWriter) cleanupDependencies() {
+	if w.dependencies == nil {
+		return
+	}
+	if p := recover(); p != nil {
+		w.dependencies.cleanup(fmt.Errorf("chimera: panic while handling request: %v", p))
+		panic(p)
+	}
+	w.dependencies.cleanup(w.respError)
+}
+
+// cleanup calls the cleanup functions of every resolved dependency in reverse order
+func (r *dependencyResolver) cleanup(err error) {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		switch f := r.cleanups[i].Interface().(type) {
+		case func():
+			f()
+		case func(error):
+			f(err)
+		}
+	}
+	r.cleanups = nil
+}
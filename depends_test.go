@@ -0,0 +1,192 @@
+package chimera_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestDBTx struct {
+	done bool
+	err  error
+}
+
+type TestAuthParams struct {
+	Token string `param:"Authorization,in=header"`
+}
+
+type TestClaims struct {
+	Subject string
+}
+
+type TestUser struct {
+	Name string
+	Tx   *TestDBTx
+}
+
+type TestDependsParams struct {
+	ID   string    `param:"id,in=path"`
+	User TestUser  `depends:""`
+	Tx   *TestDBTx `depends:""`
+}
+
+func TestDependencies(t *testing.T) {
+	api := chimera.NewAPI()
+	txs := make([]*TestDBTx, 0)
+	api.Provide(func(req *http.Request) (*TestDBTx, func(error), error) {
+		tx := &TestDBTx{}
+		txs = append(txs, tx)
+		return tx, func(err error) {
+			tx.done = true
+			tx.err = err
+		}, nil
+	})
+	api.Provide(func(params TestAuthParams) (TestClaims, error) {
+		if params.Token == "" {
+			return TestClaims{}, chimera.APIError{StatusCode: http.StatusUnauthorized}
+		}
+		return TestClaims{Subject: params.Token}, nil
+	}, map[string][]string{"bearerAuth": {}})
+	api.Provide(func(ctx context.Context, claims TestClaims, tx *TestDBTx) TestUser {
+		assert.NotNil(t, ctx)
+		return TestUser{Name: claims.Subject, Tx: tx}
+	}, map[string][]string{"apiKey": {}})
+
+	route := chimera.Get(api, "/users/{id}", func(req *chimera.NoBodyRequest[TestDependsParams]) (*chimera.EmptyResponse, error) {
+		assert.Equal(t, "abc", req.Params.ID)
+		assert.Equal(t, "bob", req.Params.User.Name)
+		// dependencies are resolved once per request
+		assert.Same(t, req.Params.Tx, req.Params.User.Tx)
+		assert.False(t, req.Params.Tx.done)
+		if req.Params.ID == "fail" {
+			return nil, errors.New("failed")
+		}
+		return nil, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/abc", nil)
+	req.Header.Set("Authorization", "bob")
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, txs, 1)
+	// cleanup runs after the response is written
+	assert.True(t, txs[0].done)
+	assert.NoError(t, txs[0].err)
+
+	// cleanup gets the error returned by the handler
+	chimera.Get(api, "/fail/{id}", func(req *chimera.NoBodyRequest[TestDependsParams]) (*chimera.EmptyResponse, error) {
+		return nil, errors.New("failed")
+	})
+	req = httptest.NewRequest(http.MethodGet, "/fail/fail", nil)
+	req.Header.Set("Authorization", "bob")
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Len(t, txs, 2)
+	assert.True(t, txs[1].done)
+	assert.EqualError(t, txs[1].err, "failed")
+
+	// cleanup gets panics as errors before the panic continues
+	chimera.Get(api, "/panic/{id}", func(req *chimera.NoBodyRequest[TestDependsParams]) (*chimera.EmptyResponse, error) {
+		panic("boom")
+	})
+	req = httptest.NewRequest(http.MethodGet, "/panic/abc", nil)
+	req.Header.Set("Authorization", "bob")
+	assert.PanicsWithValue(t, "boom", func() {
+		api.ServeHTTP(httptest.NewRecorder(), req)
+	})
+	assert.Len(t, txs, 3)
+	assert.True(t, txs[2].done)
+	assert.ErrorContains(t, txs[2].err, "boom")
+
+	// provider errors are returned as the response
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/abc", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// the params and security of providers are added to the operation
+	names := make([]string, 0)
+	for _, param := range route.OpenAPIOperationSpec().Parameters {
+		names = append(names, param.Name)
+	}
+	assert.Equal(t, []string{"id", "Authorization"}, names)
+
+	// groups can replace providers
+	group := api.Group("/group").WithOperationDefaults(chimera.OperationDefaults{
+		Security: []map[string][]string{{"oauth": {"read"}}, {"basic": {}}},
+	})
+	group.Provide(func() TestClaims {
+		return TestClaims{Subject: "group"}
+	})
+	chimera.Get(group, "/{id}", func(req *chimera.NoBodyRequest[TestDependsParams]) (*chimera.EmptyResponse, error) {
+		assert.Equal(t, "group", req.Params.User.Name)
+		return nil, nil
+	})
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/group/abc", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the security of every provider is required (on top of every default security requirement)
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	spec := chimera.OpenAPI{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, []map[string][]string{{"bearerAuth": {}, "apiKey": {}}}, spec.Paths["/users/{id}"].Get.Security)
+	assert.Equal(t, []map[string][]string{
+		{"oauth": {"read"}, "apiKey": {}},
+		{"basic": {}, "apiKey": {}},
+	}, spec.Paths["/group/{id}"].Get.Security)
+	assert.Empty(t, route.OpenAPIOperationSpec().Security)
+
+	// providers are resolved when a route is added so later ones dont replace the params it documents
+	api.Provide(func() TestAuthParams {
+		return TestAuthParams{Token: "provided"}
+	})
+	req = httptest.NewRequest(http.MethodGet, "/users/abc", nil)
+	req.Header.Set("Authorization", "bob")
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// missing providers and cycles panic when the route is added
+	type missing struct {
+		Value *int `depends:""`
+	}
+	assert.Panics(t, func() {
+		chimera.Get(api, "/missing", func(req *chimera.NoBodyRequest[missing]) (*chimera.EmptyResponse, error) {
+			return nil, nil
+		})
+	})
+	// structs without param tags arent params
+	type config struct {
+		Port int
+	}
+	type missingStruct struct {
+		Config config `depends:""`
+	}
+	assert.PanicsWithValue(t, "chimera: no dependency provider for chimera_test.config", func() {
+		chimera.Get(api, "/missing-struct", func(req *chimera.NoBodyRequest[missingStruct]) (*chimera.EmptyResponse, error) {
+			return nil, nil
+		})
+	})
+	api.Provide(func(s string) int { return len(s) })
+	api.Provide(func(i int) string { return "" })
+	type cycle struct {
+		Value int `depends:""`
+	}
+	assert.Panics(t, func() {
+		chimera.Get(api, "/cycle", func(req *chimera.NoBodyRequest[cycle]) (*chimera.EmptyResponse, error) {
+			return nil, nil
+		})
+	})
+	assert.Panics(t, func() {
+		api.Provide(func() (error, int) { return nil, 0 })
+	})
+}
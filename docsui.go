@@ -0,0 +1,183 @@
+package chimera
+
+import (
+	"encoding/json"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/swaggest/swgui"
+	"github.com/swaggest/swgui/v5emb"
+)
+
+const (
+	defaultSpecPath = "/openapi.json"
+	defaultUIPath   = "/docs"
+
+	redocCDN     = "https://cdn.jsdelivr.net/npm/redoc@2.1.5/bundles/redoc.standalone.js"
+	redocBundle  = "redoc.standalone.js"
+	scalarCDN    = "https://cdn.jsdelivr.net/npm/@scalar/api-reference@1.25.0/dist/browser/standalone.js"
+	scalarBundle = "standalone.js"
+)
+
+// APIOption configures an API created by NewAPI
+type APIOption func(*API)
+
+// DocsUI creates the handler that serves a docs UI at basePath for the spec served at specURL
+type DocsUI func(title, specURL, basePath string, config map[string]any) http.Handler
+
+// DocsOptions controls how the OpenAPI spec and docs UI are served by the root API
+type DocsOptions struct {
+	// SpecPath is the path the JSON spec is served at (defaults to /openapi.json),
+	// the YAML spec is served at the same path with a .yaml extension
+	SpecPath string
+	// Spec30Path is the path the spec converted to OpenAPI 3.0 is served at (i.e. /openapi-3.0.json),
+	// it is not served if empty. Anything that can't be converted is dropped (see OpenAPI.As30)
+	Spec30Path string
+	// UIPath is the path the docs UI is served at (defaults to /docs)
+	UIPath string
+	// UI is the docs UI to serve (defaults to SwaggerUI)
+	UI DocsUI
+	// UIConfig is passed to the docs UI (i.e. {"persistAuthorization": true} for SwaggerUI)
+	UIConfig map[string]any
+	// Middleware wraps the spec and docs UI handlers (i.e. for authentication)
+	Middleware []func(http.Handler) http.Handler
+	// DisableSpec stops the spec from being served (this also disables the docs UI)
+	DisableSpec bool
+	// DisableUI stops the docs UI from being served
+	DisableUI bool
+}
+
+// WithDocs configures how the OpenAPI spec and docs UI are served
+func WithDocs(opts DocsOptions) APIOption {
+	return func(a *API) {
+		if opts.SpecPath == "" {
+			opts.SpecPath = defaultSpecPath
+		}
+		if opts.UIPath == "" {
+			opts.UIPath = defaultUIPath
+		}
+		if opts.UI == nil {
+			opts.UI = SwaggerUI
+		}
+		a.docs = opts
+	}
+}
+
+// WithoutDocs stops the OpenAPI spec and docs UI from being served (they can still be exported using WriteSpec)
+func WithoutDocs() APIOption {
+	return WithDocs(DocsOptions{DisableSpec: true, DisableUI: true})
+}
+
+// yamlSpecPath is the path the YAML spec is served at
+func (d *DocsOptions) yamlSpecPath() string {
+	return strings.TrimSuffix(d.SpecPath, ".json") + ".yaml"
+}
+
+// wrap applies the docs middleware to a handler
+func (d *DocsOptions) wrap(handler http.Handler) http.Handler {
+	for i := len(d.Middleware) - 1; i >= 0; i-- {
+		handler = d.Middleware[i](handler)
+	}
+	return handler
+}
+
+// SwaggerUI serves an embedded Swagger UI, each key in config is a SwaggerUIBundle option
+func SwaggerUI(title, specURL, basePath string, config map[string]any) http.Handler {
+	settings := make(map[string]string)
+	for key, value := range config {
+		// swgui expects plain javascript values
+		js, err := json.Marshal(value)
+		if err == nil {
+			settings[key] = string(js)
+		}
+	}
+	return v5emb.NewWithConfig(swgui.Config{SettingsUI: settings})(title, specURL, basePath)
+}
+
+// RedocUI serves Redoc, if assets is not nil it must contain redoc.standalone.js which is served
+// alongside the UI. If assets is nil the script is loaded by the browser from jsdelivr (redoc@2.1.5)
+// so the UI does not work offline and trusts the CDN, pass assets (i.e. using go:embed) to avoid both.
+// config is passed as the Redoc options.
+func RedocUI(assets fs.FS) DocsUI {
+	return scriptUI(assets, redocBundle, redocCDN, redocTemplate)
+}
+
+// ScalarUI serves Scalar, if assets is not nil it must contain standalone.js (from @scalar/api-reference)
+// which is served alongside the UI. If assets is nil the script is loaded by the browser from jsdelivr (@scalar/api-reference@1.25.0)
+// so the UI does not work offline and trusts the CDN, pass assets (i.e. using go:embed) to avoid both.
+// config is passed as the Scalar configuration.
+func ScalarUI(assets fs.FS) DocsUI {
+	return scriptUI(assets, scalarBundle, scalarCDN, scalarTemplate)
+}
+
+var (
+	redocTemplate = template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>{{ .Title }}</title>
+<meta charset="utf-8"/>
+<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+<div id="redoc"></div>
+<script src="{{ .Script }}"></script>
+<script>Redoc.init({{ .SpecURL }}, {{ .Config }}, document.getElementById("redoc"))</script>
+</body>
+</html>
+`))
+	scalarTemplate = template.Must(template.New("scalar").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>{{ .Title }}</title>
+<meta charset="utf-8"/>
+<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+<script id="api-reference" data-url="{{ .SpecURL }}" data-configuration="{{ .ConfigJSON }}"></script>
+<script src="{{ .Script }}"></script>
+</body>
+</html>
+`))
+)
+
+// scriptUI creates a DocsUI that renders tmpl and optionally serves its script from assets
+// (which must contain bundle, otherwise the page would silently fail to load)
+func scriptUI(assets fs.FS, bundle, cdn string, tmpl *template.Template) DocsUI {
+	if assets != nil {
+		if _, err := fs.Stat(assets, bundle); err != nil {
+			panic("chimera: docs UI assets are missing " + bundle)
+		}
+	}
+	return func(title, specURL, basePath string, config map[string]any) http.Handler {
+		basePath = strings.TrimSuffix(basePath, "/")
+		script := cdn
+		if assets != nil {
+			script = basePath + "/" + bundle
+		}
+		if config == nil {
+			config = make(map[string]any)
+		}
+		configJSON, _ := json.Marshal(config)
+		data := map[string]any{
+			"Title":      title,
+			"SpecURL":    specURL,
+			"Script":     script,
+			"Config":     config,
+			"ConfigJSON": string(configJSON),
+		}
+		var files http.Handler
+		if assets != nil {
+			files = http.StripPrefix(basePath, http.FileServer(http.FS(assets)))
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if files != nil && r.URL.Path == basePath+"/"+bundle {
+				files.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			tmpl.Execute(w, data)
+		})
+	}
+}
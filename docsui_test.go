@@ -0,0 +1,107 @@
+package chimera_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+func getDocs(t *testing.T, api *chimera.API, path string, header ...string) (int, string) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	recorder := httptest.NewRecorder()
+	api.ServeHTTP(recorder, req)
+	body, _ := io.ReadAll(recorder.Result().Body)
+	return recorder.Code, string(body)
+}
+
+func TestDocsOptions(t *testing.T) {
+	api := chimera.NewAPI()
+	chimera.Get(api, "/items", func(*chimera.EmptyRequest) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+	code, _ := getDocs(t, api, "/openapi.json")
+	assert.Equal(t, 200, code)
+	code, _ = getDocs(t, api, "/openapi.yaml")
+	assert.Equal(t, 200, code)
+	code, body := getDocs(t, api, "/docs")
+	assert.Equal(t, 200, code)
+	assert.Contains(t, body, "swagger")
+
+	api = chimera.NewAPI(chimera.WithDocs(chimera.DocsOptions{UIConfig: map[string]any{"persistAuthorization": true}}))
+	code, body = getDocs(t, api, "/docs/")
+	assert.Equal(t, 200, code)
+	assert.Contains(t, body, "persistAuthorization: true")
+
+	api = chimera.NewAPI(chimera.WithoutDocs())
+	chimera.Get(api, "/docs", func(*chimera.EmptyRequest) (*chimera.Response, error) {
+		return &chimera.Response{Body: []byte("mine")}, nil
+	})
+	code, _ = getDocs(t, api, "/openapi.json")
+	assert.Equal(t, 404, code)
+	code, body = getDocs(t, api, "/docs")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "mine", body)
+
+	requireKey := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Key") != "key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	api = chimera.NewAPI(chimera.WithDocs(chimera.DocsOptions{
+		SpecPath:   "/api/spec.json",
+		UIPath:     "/api/reference",
+		UI:         chimera.RedocUI(fstest.MapFS{"redoc.standalone.js": {Data: []byte("// redoc")}}),
+		UIConfig:   map[string]any{"hideDownloadButton": true},
+		Middleware: []func(http.Handler) http.Handler{requireKey},
+	}))
+	chimera.Get(api, "/items", func(*chimera.EmptyRequest) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+	code, _ = getDocs(t, api, "/api/spec.json")
+	assert.Equal(t, 401, code)
+	code, body = getDocs(t, api, "/api/spec.json", "X-Key", "key")
+	assert.Equal(t, 200, code)
+	assert.Contains(t, body, `"/items"`)
+	code, _ = getDocs(t, api, "/api/spec.yaml", "X-Key", "key")
+	assert.Equal(t, 200, code)
+	code, _ = getDocs(t, api, "/openapi.json", "X-Key", "key")
+	assert.Equal(t, 404, code)
+	code, body = getDocs(t, api, "/api/reference", "X-Key", "key")
+	assert.Equal(t, 200, code)
+	assert.Contains(t, body, `src="/api/reference/redoc.standalone.js"`)
+	assert.Contains(t, body, `Redoc.init("/api/spec.json", {"hideDownloadButton":true}`)
+	code, body = getDocs(t, api, "/api/reference/redoc.standalone.js", "X-Key", "key")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "// redoc", body)
+
+	api = chimera.NewAPI(chimera.WithDocs(chimera.DocsOptions{
+		UI:       chimera.ScalarUI(nil),
+		UIConfig: map[string]any{"theme": "purple"},
+	}))
+	chimera.Get(api, "/items", func(*chimera.EmptyRequest) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+	code, body = getDocs(t, api, "/docs")
+	assert.Equal(t, 200, code)
+	assert.Contains(t, body, `data-url="/openapi.json"`)
+	assert.Contains(t, body, `data-configuration="{&#34;theme&#34;:&#34;purple&#34;}"`)
+	// without assets the script comes from a CDN (with a pinned version)
+	assert.Contains(t, body, `src="https://cdn.jsdelivr.net/npm/@scalar/api-reference@1.25.0/dist/browser/standalone.js"`)
+
+	// assets without the bundle would serve a broken page
+	assert.PanicsWithValue(t, "chimera: docs UI assets are missing standalone.js", func() {
+		chimera.ScalarUI(fstest.MapFS{"redoc.standalone.js": {Data: []byte("// redoc")}})
+	})
+}
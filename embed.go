@@ -0,0 +1,271 @@
+package chimera
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/matt1484/spectagular"
+)
+
+// paramTag is a param field of a Params struct, index is the path to the field
+// through any embedded structs (like reflect.StructField.Index)
+type paramTag struct {
+	spectagular.FieldTag[ParamStructTag]
+	index []int
+}
+
+// paramTagCache caches the param tags of Params structs with embedded structs flattened
+type paramTagCache struct {
+	fields *spectagular.StructTagCache[ParamStructTag]
+	tags   sync.Map
+}
+
+// newParamTagCache creates a paramTagCache for the "param" struct tag
+func newParamTagCache() *paramTagCache {
+	return &paramTagCache{
+		fields: initTagCache[ParamStructTag]("param"),
+	}
+}
+
+// Get returns the cached param tags of a type
+func (c *paramTagCache) Get(t reflect.Type) ([]paramTag, bool) {
+	tags, ok := c.tags.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return tags.([]paramTag), true
+}
+
+// GetOrAdd returns the cached param tags of a type, parsing them if needed
+func (c *paramTagCache) GetOrAdd(t reflect.Type) ([]paramTag, error) {
+	if tags, ok := c.Get(t); ok {
+		return tags, nil
+	}
+	tags, err := c.flatten(t, nil, map[reflect.Type]struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	tags = shadowParamTags(tags)
+	actual, _ := c.tags.LoadOrStore(t, tags)
+	return actual.([]paramTag), nil
+}
+
+// flatten collects the param tags of a struct and every struct it embeds (in field order)
+func (c *paramTagCache) flatten(t reflect.Type, index []int, seen map[reflect.Type]struct{}) ([]paramTag, error) {
+	if _, ok := seen[t]; ok {
+		return nil, nil
+	}
+	seen[t] = struct{}{}
+	defer delete(seen, t)
+	paramType, fieldIndexes := paramFieldsType(t)
+	fieldTags, err := c.fields.GetOrAdd(paramType)
+	if err != nil {
+		return nil, err
+	}
+	byField := make(map[int]spectagular.FieldTag[ParamStructTag])
+	for _, tag := range fieldTags {
+		if fieldIndexes != nil {
+			tag.FieldIndex = fieldIndexes[tag.FieldIndex]
+		}
+		byField[tag.FieldIndex] = tag
+	}
+	var tags []paramTag
+	for i := 0; i < t.NumField(); i++ {
+		fieldIndex := append(append([]int{}, index...), i)
+		if tag, ok := byField[i]; ok {
+			tags = append(tags, paramTag{FieldTag: tag, index: fieldIndex})
+			continue
+		}
+		embedded, ok := embeddedStruct(t.Field(i))
+		if !ok || t.Field(i).Tag.Get("param") == spectagular.SkipTag {
+			continue
+		}
+		inner, err := c.flatten(embedded, fieldIndex, seen)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, inner...)
+	}
+	return tags, nil
+}
+
+// paramFieldsType returns the type to parse the param tags of a struct with. Dependency fields (see API.Provide)
+// arent params so structs with them are parsed using a struct of their other fields, the index of each of its fields
+// in the original struct is returned as well (or nil if the struct is used as is)
+func paramFieldsType(t reflect.Type) (reflect.Type, []int) {
+	hasDependencies := false
+	for i := 0; i < t.NumField(); i++ {
+		hasDependencies = hasDependencies || isDependencyField(t.Field(i))
+	}
+	if !hasDependencies {
+		return t, nil
+	}
+	fields := make([]reflect.StructField, 0)
+	indexes := make([]int, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// spectagular skips these anyways
+		if isDependencyField(field) || !field.IsExported() || field.Anonymous {
+			continue
+		}
+		field.Index = nil
+		field.Offset = 0
+		fields = append(fields, field)
+		indexes = append(indexes, i)
+	}
+	return reflect.StructOf(fields), indexes
+}
+
+// embeddedStruct returns the struct type of an embedded field (or pointer embed)
+// unexported pointer embeds are skipped since they cant be allocated
+func embeddedStruct(field reflect.StructField) (reflect.Type, bool) {
+	if !field.Anonymous {
+		return nil, false
+	}
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		if !field.IsExported() {
+			return nil, false
+		}
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
+}
+
+// shadowParamTags removes embedded params that share a name (and location) with a shallower param
+func shadowParamTags(tags []paramTag) []paramTag {
+	keys := make([]string, len(tags))
+	indexes := make([][]int, len(tags))
+	for i, tag := range tags {
+		if tag.Value.Name == spectagular.EmptyTag || tag.Value.Name == spectagular.SkipTag {
+			continue
+		}
+		name := tag.Value.Name
+		if tag.Value.In == HeaderIn {
+			name = strings.ToLower(name)
+		}
+		keys[i] = fmt.Sprint(tag.Value.In) + ":" + name
+		indexes[i] = tag.index
+	}
+	var flat []paramTag
+	for i, hidden := range shadowed(keys, indexes) {
+		if !hidden {
+			flat = append(flat, tags[i])
+		}
+	}
+	return flat
+}
+
+// paramPropField is a property of a struct param, index is the path to the field through any embedded structs
+type paramPropField struct {
+	name  string
+	index []int
+}
+
+// paramPropFields returns the props of a struct param with embedded structs flattened
+func paramPropFields(t reflect.Type) []paramPropField {
+	var collect func(t reflect.Type, index []int, seen map[reflect.Type]struct{}) []paramPropField
+	collect = func(t reflect.Type, index []int, seen map[reflect.Type]struct{}) []paramPropField {
+		if _, ok := seen[t]; ok {
+			return nil
+		}
+		seen[t] = struct{}{}
+		defer delete(seen, t)
+		fieldTags, _ := spectagular.ParseTagsForType[paramPropTag]("prop", t)
+		names := make(map[int]string)
+		for _, ft := range fieldTags {
+			names[ft.FieldIndex] = ft.Value.Name
+		}
+		var props []paramPropField
+		for i := 0; i < t.NumField(); i++ {
+			fieldIndex := append(append([]int{}, index...), i)
+			if name, ok := names[i]; ok {
+				props = append(props, paramPropField{name: name, index: fieldIndex})
+				continue
+			}
+			field := t.Field(i)
+			if embedded, ok := embeddedStruct(field); ok && field.Tag.Get("prop") != spectagular.SkipTag {
+				props = append(props, collect(embedded, fieldIndex, seen)...)
+			}
+		}
+		return props
+	}
+	props := collect(t, nil, map[reflect.Type]struct{}{})
+	keys := make([]string, len(props))
+	indexes := make([][]int, len(props))
+	for i, prop := range props {
+		keys[i] = prop.name
+		indexes[i] = prop.index
+	}
+	var flat []paramPropField
+	for i, hidden := range shadowed(keys, indexes) {
+		if !hidden {
+			flat = append(flat, props[i])
+		}
+	}
+	return flat
+}
+
+// shadowed applies Go's shadowing rules to fields with the given keys (names) and index paths:
+// a field is hidden by a shallower field with the same key and fields that conflict at the same depth
+// in different embedded structs are ambiguous so both are hidden. Empty keys are never hidden
+func shadowed(keys []string, indexes [][]int) []bool {
+	type candidate struct {
+		depth     int
+		parent    string
+		ambiguous bool
+	}
+	best := make(map[string]*candidate)
+	for i, key := range keys {
+		if key == "" {
+			continue
+		}
+		c := &candidate{depth: len(indexes[i]), parent: fmt.Sprint(indexes[i][:len(indexes[i])-1])}
+		b, ok := best[key]
+		switch {
+		case !ok || c.depth < b.depth:
+			best[key] = c
+		case c.depth == b.depth && c.parent != b.parent:
+			b.ambiguous = true
+		}
+	}
+	hidden := make([]bool, len(keys))
+	for i, key := range keys {
+		if key == "" {
+			continue
+		}
+		b := best[key]
+		hidden[i] = b.depth != len(indexes[i]) || b.ambiguous
+	}
+	return hidden
+}
+
+// paramField returns the field at index, allocating any nil embedded pointers along the way
+func paramField(value reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value
+}
+
+// lookupParamField returns the field at index or false if an embedded pointer along the way is nil
+func lookupParamField(value reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return value, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value, true
+}
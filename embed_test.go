@@ -0,0 +1,116 @@
+package chimera_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestPageParams struct {
+	Page  int `param:"page,in=query"`
+	Limit int `param:"limit,in=query"`
+}
+
+type TestTenantHeaders struct {
+	Tenant string `param:"X-Tenant,in=header"`
+}
+
+type TestSortByName struct {
+	Sort string `param:"sort,in=query"`
+}
+
+type TestSortByDate struct {
+	Sort string `param:"sort,in=query"`
+}
+
+type TestBaseFilter struct {
+	Status string `prop:"status"`
+}
+
+type TestEmbeddedFilter struct {
+	TestBaseFilter
+	Name string `prop:"name"`
+}
+
+type TestEmbeddedParams struct {
+	TestPageParams
+	*TestTenantHeaders
+	TestSortByName
+	TestSortByDate
+	ID     string             `param:"id,in=path"`
+	Limit  uint8              `param:"limit,in=query"`
+	Filter TestEmbeddedFilter `param:"filter,in=query,style=deepObject"`
+}
+
+type TestEmbeddedResponseParams struct {
+	*TestTenantHeaders
+	Version string `param:"X-Version,in=header"`
+}
+
+func TestEmbeddedParamsBinding(t *testing.T) {
+	api := chimera.NewAPI()
+	route := chimera.Get(api, "/embedded/{id}", func(req *chimera.NoBodyRequest[TestEmbeddedParams]) (*chimera.NoBodyResponse[TestEmbeddedResponseParams], error) {
+		assert.Equal(t, "abc", req.Params.ID)
+		assert.Equal(t, 2, req.Params.Page)
+		// the outer limit shadows the embedded one
+		assert.Equal(t, uint8(10), req.Params.Limit)
+		assert.Equal(t, 0, req.Params.TestPageParams.Limit)
+		// pointer embeds are allocated
+		assert.Equal(t, "acme", req.Params.Tenant)
+		// ambiguous params are dropped
+		assert.Equal(t, "", req.Params.TestSortByName.Sort)
+		assert.Equal(t, "", req.Params.TestSortByDate.Sort)
+		// struct params flatten embedded props too
+		assert.Equal(t, "open", req.Params.Filter.Status)
+		assert.Equal(t, "x", req.Params.Filter.Name)
+		return &chimera.NoBodyResponse[TestEmbeddedResponseParams]{
+			Params: TestEmbeddedResponseParams{
+				TestTenantHeaders: req.Params.TestTenantHeaders,
+				Version:           "1",
+			},
+		}, nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/embedded/abc?page=2&limit=10&sort=name&filter[status]=open&filter[name]=x", nil)
+	req.Header.Set("X-Tenant", "acme")
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "acme", w.Header().Get("X-Tenant"))
+	assert.Equal(t, "1", w.Header().Get("X-Version"))
+
+	// embedded params are in the spec once, in field order
+	names := make([]string, 0)
+	for _, param := range route.OpenAPIOperationSpec().Parameters {
+		names = append(names, param.Name)
+		if param.Name == "limit" {
+			assert.Equal(t, "integer", string(param.Schema.Type))
+		}
+	}
+	assert.Equal(t, []string{"page", "X-Tenant", "id", "limit", "filter"}, names)
+
+	// nil pointer embeds are treated as missing params
+	header, err := chimera.MarshalParams(&TestEmbeddedResponseParams{Version: "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.Header{"X-Version": {"2"}}, header)
+
+	// responses can be read back into embedded structs
+	params := TestEmbeddedResponseParams{}
+	assert.NoError(t, chimera.UnmarshalResponseParams(w.Result(), &params))
+	assert.Equal(t, "acme", params.Tenant)
+	assert.Equal(t, "1", params.Version)
+
+	// requests can be written from embedded structs
+	req = httptest.NewRequest(http.MethodGet, "/embedded/{id}", nil)
+	assert.NoError(t, chimera.MarshalRequestParams(req, &TestEmbeddedParams{
+		TestPageParams: TestPageParams{Page: 3},
+		ID:             "xyz",
+		Limit:          5,
+	}))
+	assert.Equal(t, "/embedded/xyz", req.URL.Path)
+	assert.Equal(t, "3", req.URL.Query().Get("page"))
+	assert.Equal(t, "5", req.URL.Query().Get("limit"))
+	assert.Empty(t, req.Header.Get("X-Tenant"))
+}
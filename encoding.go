@@ -0,0 +1,212 @@
+package chimera
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	jsonEncoding       = "json"
+	base64Encoding     = "base64"
+	base64JSONEncoding = "base64json"
+	jwtEncoding        = "jwt"
+)
+
+var (
+	// jwtHeader is the header of the (unsigned) JWTs written by jwt encoded params
+	jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+)
+
+// JWTVerifier verifies a token (i.e. its signature, issuer and expiry) before the claims of a jwt encoded param are decoded,
+// tokens that fail are rejected with a 401 (or the APIError returned by the verifier)
+type JWTVerifier func(ctx context.Context, token string) error
+
+// jwtVerifierContextKey is the context key of the JWTVerifier used to read the params of a request
+type jwtVerifierContextKey struct{}
+
+// WithJWTVerifier sets the verifier of jwt encoded request params, without one (or WithUnverifiedJWT) every token
+// is rejected with a 401. Groups and mounted APIs without their own verifier use their parent's
+func WithJWTVerifier(verifier JWTVerifier) APIOption {
+	return func(a *API) {
+		a.verifyJWT = verifier
+	}
+}
+
+// WithUnverifiedJWT decodes the claims of jwt encoded request params without verifying their tokens,
+// this should only be used when tokens were already verified (i.e. by a gateway) since the claims are untrusted otherwise
+func WithUnverifiedJWT() APIOption {
+	return WithJWTVerifier(func(context.Context, string) error {
+		return nil
+	})
+}
+
+// rejectJWT is the JWTVerifier of requests without one so the claims of their tokens are never used unverified
+func rejectJWT(context.Context, string) error {
+	return errors.New("chimera: no JWTVerifier for the request (see WithJWTVerifier and WithUnverifiedJWT)")
+}
+
+// jwtVerifier gets the nearest JWTVerifier up the parent chain of an API
+func (a *API) jwtVerifier() JWTVerifier {
+	for api := a; api != nil; api = api.parent {
+		if api.verifyJWT != nil {
+			return api.verifyJWT
+		}
+	}
+	return nil
+}
+
+// withJWTVerifier returns a copy of a jwt encoded param's tag that verifies tokens using the verifier of ctx
+// (tokens are rejected if there is none)
+func (p *ParamStructTag) withJWTVerifier(ctx context.Context) *ParamStructTag {
+	if p.Encoding != jwtEncoding {
+		return p
+	}
+	verifier, _ := ctx.Value(jwtVerifierContextKey{}).(JWTVerifier)
+	if verifier == nil {
+		verifier = rejectJWT
+	}
+	tag := *p
+	tag.verifyJWT = func(token string) error {
+		return verifier(ctx, token)
+	}
+	return &tag
+}
+
+// initEncoding validates the encoding option of a param and sets up its schema, addr is a pointer to the field
+func (p *ParamStructTag) initEncoding(addr reflect.Value) {
+	if p.In != HeaderIn && p.In != CookieIn {
+		panic("chimera: encoding is only supported for header and cookie params (" + p.Name + ")")
+	}
+	inner := newReflector(jsonschema.Reflector{}).Reflect(addr.Interface())
+	schema := &jsonschema.Schema{Type: "string"}
+	switch p.Encoding {
+	case jsonEncoding:
+		if p.In == CookieIn {
+			// quotes arent allowed in cookie values
+			panic("chimera: json encoding is not supported for cookies, use base64json for " + p.Name)
+		}
+		schema.ContentMediaType = "application/json"
+		schema.ContentSchema = inner
+	case base64Encoding:
+		kind := addr.Elem().Kind()
+		if kind == reflect.Struct || kind == reflect.Map || (kind == reflect.Slice && addr.Elem().Type().Elem().Kind() != reflect.Uint8) {
+			panic("chimera: base64 encoding only supports bytes, strings and primitives, use base64json for " + p.Name)
+		}
+		schema.ContentEncoding = "base64"
+	case base64JSONEncoding:
+		schema.ContentEncoding = "base64"
+		schema.ContentMediaType = "application/json"
+		schema.ContentSchema = inner
+	case jwtEncoding:
+		// a JWT is described as a [header, claims] array (see the JSON schema validation spec)
+		schema.ContentMediaType = "application/jwt"
+		schema.ContentSchema = &jsonschema.Schema{
+			Type: "array",
+			PrefixItems: []*jsonschema.Schema{
+				{Type: "object"},
+				inner,
+			},
+		}
+	default:
+		panic("chimera: invalid encoding " + p.Encoding + " for param " + p.Name)
+	}
+	p.schemaType = encodedType
+	p.schema = schema
+}
+
+// decodeBase64 decodes standard or url safe base64 with or without padding
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.TrimRight(value, "=")
+	if strings.ContainsAny(value, "-_") {
+		return base64.RawURLEncoding.DecodeString(value)
+	}
+	return base64.RawStdEncoding.DecodeString(value)
+}
+
+// unmarshalEncodedParam decodes a param value using the param's encoding
+func unmarshalEncodedParam(param string, tag *ParamStructTag, addr reflect.Value) error {
+	var err error
+	switch tag.Encoding {
+	case jsonEncoding:
+		err = json.Unmarshal([]byte(param), addr.Interface())
+	case base64Encoding:
+		var decoded []byte
+		decoded, err = decodeBase64(param)
+		if err == nil {
+			if addr.Elem().Kind() == reflect.Slice {
+				addr.Elem().SetBytes(decoded)
+			} else {
+				var val reflect.Value
+				val, err = decodePrimitiveString(string(decoded), addr.Elem().Kind())
+				if err == nil {
+					addr.Elem().Set(val.Convert(addr.Elem().Type()))
+				}
+			}
+		}
+	case base64JSONEncoding:
+		var decoded []byte
+		decoded, err = decodeBase64(param)
+		if err == nil {
+			err = json.Unmarshal(decoded, addr.Interface())
+		}
+	case jwtEncoding:
+		if scheme, token, ok := strings.Cut(param, " "); ok && strings.EqualFold(scheme, "bearer") {
+			param = token
+		}
+		parts := strings.Split(param, ".")
+		if len(parts) != 3 {
+			err = errors.New("invalid jwt")
+			break
+		}
+		if tag.verifyJWT != nil {
+			if verifyErr := tag.verifyJWT(param); verifyErr != nil {
+				if apiErr, ok := verifyErr.(APIError); ok {
+					return apiErr
+				}
+				return APIError{
+					StatusCode: http.StatusUnauthorized,
+					Body:       []byte(fmt.Sprintf("%s parameter %s is invalid", marshalIn(tag.In), tag.Name)),
+				}
+			}
+		}
+		var claims []byte
+		claims, err = decodeBase64(parts[1])
+		if err == nil {
+			err = json.Unmarshal(claims, addr.Interface())
+		}
+	}
+	if err != nil {
+		return NewInvalidParamError(marshalIn(tag.In), tag.Name, param)
+	}
+	return nil
+}
+
+// marshalEncodedParam is the inverse of unmarshalEncodedParam and encodes a value using the param's encoding
+func marshalEncodedParam(tag *ParamStructTag, addr reflect.Value) (string, error) {
+	switch tag.Encoding {
+	case jsonEncoding:
+		b, err := json.Marshal(addr.Interface())
+		return string(b), err
+	case base64Encoding:
+		if addr.Elem().Kind() == reflect.Slice {
+			return base64.StdEncoding.EncodeToString(addr.Elem().Bytes()), nil
+		}
+		return base64.StdEncoding.EncodeToString([]byte(marshalPrimitiveToString(addr))), nil
+	case base64JSONEncoding:
+		b, err := json.Marshal(addr.Interface())
+		return base64.StdEncoding.EncodeToString(b), err
+	case jwtEncoding:
+		// the JWT isnt signed (alg none) so it is only useful for tests or for tokens that are signed by something else
+		b, err := json.Marshal(addr.Interface())
+		return jwtHeader + "." + base64.RawURLEncoding.EncodeToString(b) + ".", err
+	}
+	return "", nil
+}
@@ -0,0 +1,178 @@
+package chimera
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	validator "github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Exampler is implemented by body and params types that provide examples for the spec.
+// For body types the keys are the names of the examples and the values are example bodies,
+// for params types the keys are param names and the values are the example of that param.
+type Exampler interface {
+	Examples() map[string]any
+}
+
+// typeExamples gets the examples of a type if it (or a pointer to it) implements Exampler
+func typeExamples(t reflect.Type) map[string]any {
+	if t == nil {
+		return nil
+	}
+	for ; t.Kind() == reflect.Pointer; t = t.Elem() {
+	}
+	if exampler, ok := reflect.New(t).Interface().(Exampler); ok {
+		return exampler.Examples()
+	}
+	return nil
+}
+
+// bodyExamples converts the examples of a body type to openapi examples (or nil if it has none)
+func bodyExamples[Body any]() *map[string]Example {
+	examples := typeExamples(reflect.TypeOf(new(Body)))
+	if len(examples) == 0 {
+		return nil
+	}
+	converted := make(map[string]Example, len(examples))
+	for name, value := range examples {
+		converted[name] = Example{Value: value}
+	}
+	return &converted
+}
+
+// parseExample converts the example of a param tag to a value matching its schema,
+// anything other than a string is parsed as JSON (i.e. example=5 or example='[1,2]')
+func parseExample(example string, schema *jsonschema.Schema) any {
+	if example == "" {
+		return nil
+	}
+	if schema == nil || schema.Type == "string" {
+		return example
+	}
+	var value any
+	if err := json.Unmarshal([]byte(example), &value); err != nil {
+		return example
+	}
+	return value
+}
+
+// exampleValidator validates examples against schemas that may reference components.schemas
+type exampleValidator struct {
+	components map[string]jsonschema.Schema
+	errs       []string
+}
+
+// validate validates an example against a schema, where describes the example in any error
+func (v *exampleValidator) validate(where string, schema *jsonschema.Schema, example any) {
+	if schema == nil || example == nil {
+		return
+	}
+	raw, err := json.Marshal(example)
+	if err != nil {
+		v.errs = append(v.errs, fmt.Sprintf("%s: %v", where, err))
+		return
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		v.errs = append(v.errs, fmt.Sprintf("%s: %v", where, err))
+		return
+	}
+	// the schema is added to a document with the components so that $refs resolve
+	doc, err := json.Marshal(map[string]any{
+		"components": map[string]any{"schemas": v.components},
+		"schema":     schema,
+	})
+	if err != nil {
+		v.errs = append(v.errs, fmt.Sprintf("%s: %v", where, err))
+		return
+	}
+	compiler := validator.NewCompiler()
+	compiler.Draft = validator.Draft2020
+	if err := compiler.AddResource("examples.json", bytes.NewReader(doc)); err != nil {
+		v.errs = append(v.errs, fmt.Sprintf("%s: %v", where, err))
+		return
+	}
+	compiled, err := compiler.Compile("examples.json#/schema")
+	if err != nil {
+		v.errs = append(v.errs, fmt.Sprintf("%s: %v", where, err))
+		return
+	}
+	if err := compiled.Validate(value); err != nil {
+		v.errs = append(v.errs, fmt.Sprintf("%s: %s", where, validationMessage(err)))
+	}
+}
+
+// validationMessage describes the leaf causes of a validation error (without the schema locations)
+func validationMessage(err error) string {
+	validationErr, ok := err.(*validator.ValidationError)
+	if !ok {
+		return err.Error()
+	}
+	messages := make([]string, 0)
+	var walk func(e *validator.ValidationError)
+	walk = func(e *validator.ValidationError) {
+		if len(e.Causes) == 0 {
+			messages = append(messages, fmt.Sprintf("'%s' %s", e.InstanceLocation, e.Message))
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(validationErr)
+	return strings.Join(messages, ", ")
+}
+
+// validateParameter validates the examples of a parameter
+func (v *exampleValidator) validateParameter(where string, param Parameter) {
+	v.validate(where, param.Schema, param.Example)
+	if param.Examples != nil {
+		for name, example := range *param.Examples {
+			v.validate(where+" (example "+name+")", param.Schema, example.Value)
+		}
+	}
+}
+
+// validateContent validates the examples of every media type in content
+func (v *exampleValidator) validateContent(where string, content map[string]MediaType) {
+	for contentType, media := range content {
+		// only json examples can be compared to their schema
+		if !strings.HasSuffix(contentType, "json") {
+			continue
+		}
+		v.validate(where+" "+contentType, media.Schema, media.Example)
+		if media.Examples != nil {
+			for name, example := range *media.Examples {
+				v.validate(where+" "+contentType+" (example "+name+")", media.Schema, example.Value)
+			}
+		}
+	}
+}
+
+// validateOperationExamples checks that every example in an operation is valid against its schema
+func validateOperationExamples(operation *Operation, components map[string]jsonschema.Schema) error {
+	v := exampleValidator{components: components}
+	if operation.RequestSpec != nil {
+		for _, param := range operation.RequestSpec.Parameters {
+			v.validateParameter(param.In+" param "+param.Name, param)
+		}
+		if operation.RequestSpec.RequestBody != nil {
+			v.validateContent("request body", operation.RequestSpec.RequestBody.Content)
+		}
+	}
+	for code, response := range operation.Responses {
+		for name, header := range response.Headers {
+			v.validateParameter("response "+code+" header "+name, header)
+		}
+		v.validateContent("response "+code, response.Content)
+	}
+	if len(v.errs) == 0 {
+		return nil
+	}
+	sort.Strings(v.errs)
+	return fmt.Errorf("invalid examples: %s", strings.Join(v.errs, "; "))
+}
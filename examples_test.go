@@ -0,0 +1,101 @@
+package chimera_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestExamplesBody struct {
+	Name  string `json:"name" jsonschema:"minLength=1"`
+	Count int    `json:"count"`
+}
+
+func (TestExamplesBody) Examples() map[string]any {
+	return map[string]any{
+		"simple": TestExamplesBody{Name: "thing", Count: 1},
+	}
+}
+
+type TestExamplesParams struct {
+	ID     int      `param:"id,in=path,example=5"`
+	Filter string   `param:"filter,in=query,example=abc"`
+	Tags   []string `param:"tags,in=query,example='[\"a\",\"b\"]'"`
+	Limit  int      `param:"limit,in=query"`
+}
+
+func (*TestExamplesParams) Examples() map[string]any {
+	return map[string]any{
+		"limit": 10,
+	}
+}
+
+type TestInvalidExamplesBody struct {
+	Name string `json:"name" jsonschema:"minLength=1"`
+}
+
+func (TestInvalidExamplesBody) Examples() map[string]any {
+	return map[string]any{
+		"empty": TestInvalidExamplesBody{},
+	}
+}
+
+type TestInvalidExamplesParams struct {
+	ID int `param:"id,in=path,example=abc"`
+}
+
+func TestExamples(t *testing.T) {
+	api := chimera.NewAPI()
+	route := chimera.Post(api, "/things/{id}", func(req *chimera.JSON[TestExamplesBody, TestExamplesParams]) (*chimera.JSON[TestExamplesBody, chimera.Nil], error) {
+		return &chimera.JSON[TestExamplesBody, chimera.Nil]{Body: req.Body}, nil
+	})
+	op := route.OpenAPIOperationSpec()
+	examples := map[string]any{}
+	for _, param := range op.Parameters {
+		examples[param.Name] = param.Example
+	}
+	assert.Equal(t, map[string]any{"id": float64(5), "filter": "abc", "tags": []any{"a", "b"}, "limit": 10}, examples)
+	media := op.RequestBody.Content["application/json"]
+	assert.NotNil(t, media.Examples)
+	assert.Equal(t, TestExamplesBody{Name: "thing", Count: 1}, (*media.Examples)["simple"].Value)
+	assert.NotNil(t, op.Responses["201"].Content["application/json"].Examples)
+
+	assert.PanicsWithValue(t, `chimera: POST /invalid/body has invalid examples: request body application/json (example empty): '/name' length must be >= 1, but got 0`, func() {
+		chimera.Post(api, "/invalid/body", func(req *chimera.JSON[TestInvalidExamplesBody, chimera.Nil]) (*chimera.EmptyResponse, error) {
+			return nil, nil
+		})
+	})
+	assert.Panics(t, func() {
+		chimera.Get(api, "/invalid/{id}", func(req *chimera.NoBodyRequest[TestInvalidExamplesParams]) (*chimera.EmptyResponse, error) {
+			return nil, nil
+		})
+	})
+	assert.Panics(t, func() {
+		chimera.Webhook[chimera.JSON[TestInvalidExamplesBody, chimera.Nil], chimera.EmptyResponse](api, "invalid", http.MethodPost)
+	})
+
+	// examples added to the spec of an existing route are checked too
+	invalid := chimera.Responses{"201": chimera.ResponseSpec{
+		Content: map[string]chimera.MediaType{"application/json": {
+			Schema:  &jsonschema.Schema{Type: "integer"},
+			Example: "abc",
+		}},
+	}}
+	assert.PanicsWithValue(t, `chimera: POST /things/{id} has invalid examples: response 201 application/json: '' expected integer, but got string`, func() {
+		route.WithOperation(chimera.Operation{Responses: invalid})
+	})
+	assert.Panics(t, func() {
+		route.UsingOperation(chimera.Operation{Responses: invalid})
+	})
+	assert.NotPanics(t, func() {
+		route.UsingOperation(chimera.Operation{Responses: chimera.Responses{"201": chimera.ResponseSpec{
+			Content: map[string]chimera.MediaType{"application/json": {
+				Schema:  &jsonschema.Schema{Type: "integer"},
+				Example: 5,
+			}},
+		}}})
+	})
+}
@@ -11,7 +11,10 @@ import (
 )
 
 var (
-	formBodyDecoder = form.NewDecoder()
+	_               RequestReader = new(FormRequest[Nil, Nil])
+	_               RequestWriter = new(FormRequest[Nil, Nil])
+	formBodyDecoder               = form.NewDecoder()
+	formBodyEncoder               = form.NewEncoder()
 )
 
 // FormRequest[Body, Params any] is a request type that decodes request bodies to a
@@ -30,6 +33,11 @@ func (r *FormRequest[Body, Params]) Context() context.Context {
 	return nil
 }
 
+// httpRequest returns the original http.Request (see Callback.URL)
+func (r *FormRequest[Body, Params]) httpRequest() *http.Request {
+	return r.request
+}
+
 // ReadRequest reads the body of an http request and assigns it to the Body field using
 // http.Request.ParseForm and the "go-playground/form" package.
 // This function also reads the parameters using UnmarshalParams and assigns it to the Params field.
@@ -61,6 +69,23 @@ func (r *FormRequest[Body, Params]) ReadRequest(req *http.Request) error {
 	return nil
 }
 
+// WriteRequest writes the Body field to the request using the "go-playground/form" package
+// and the Params field using MarshalRequestParams
+func (r *FormRequest[Body, Params]) WriteRequest(req *http.Request) error {
+	if _, ok := any(r.Body).(Nil); !ok {
+		values, err := formBodyEncoder.Encode(&r.Body)
+		if err != nil {
+			return err
+		}
+		setRequestBody(req, "application/x-www-form-urlencoded", []byte(values.Encode()))
+	}
+
+	if _, ok := any(r.Params).(Nil); !ok {
+		return MarshalRequestParams(req, &r.Params)
+	}
+	return nil
+}
+
 // flattenFormSchemas is kind of a jank way to convert jsonschema.Schema objects to be of a "form"
 // style using patternProperties to represent arrays/object paths
 func flattenFormSchemas(schema *jsonschema.Schema, properties map[string]*jsonschema.Schema, refs jsonschema.Definitions, prefix string) {
@@ -100,7 +125,7 @@ func (r *FormRequest[Body, Params]) OpenAPIRequestSpec() RequestSpec {
 
 	schema := RequestSpec{}
 	if bType != reflect.TypeOf(Nil{}) {
-		s := (&jsonschema.Reflector{FieldNameTag: "form"}).Reflect(new(Body))
+		s := newReflector(jsonschema.Reflector{FieldNameTag: "form"}).Reflect(new(Body))
 		// s.ID = jsonschema.ID(bType.PkgPath() + "_" + bType.Name())
 		if s.PatternProperties == nil {
 			s.PatternProperties = make(map[string]*jsonschema.Schema)
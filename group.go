@@ -0,0 +1,116 @@
+package chimera
+
+import (
+	"net/http"
+	"reflect"
+)
+
+var (
+	_ RouteContext       = new(groupRouteContext)
+	_ groupParamsContext = new(groupRouteContext)
+)
+
+// groupParamsContext is implemented by the RouteContext of requests to routes with group params
+// (it isnt part of RouteContext so that other implementations of RouteContext dont need it)
+type groupParamsContext interface {
+	GroupParams() []any
+}
+
+// groupParams is a Params type parsed for every route of an API
+type groupParams struct {
+	paramType  reflect.Type
+	parameters []Parameter
+}
+
+// WithGroupParams parses Params (usually the path params of a Group's base path) once for every request
+// to the routes of an API (including nested groups). The parsed params are available to middleware
+// through GetGroupParams and are documented as path-level parameters of every path in the API
+func WithGroupParams[Params any]() APIOption {
+	return func(a *API) {
+		paramType := reflect.TypeOf(*new(Params))
+		if paramType.Kind() != reflect.Struct {
+			panic("chimera: group params must be a struct, got " + paramType.String())
+		}
+		parameters := CacheRequestParamsType(paramType)
+		for i, p := range parameters {
+			if p.Schema != nil {
+				standardizedSchemas(p.Schema, a.openAPISpec.Components.Schemas)
+			}
+			parameters[i] = p
+		}
+		a.groupParams = &groupParams{
+			paramType:  paramType,
+			parameters: parameters,
+		}
+	}
+}
+
+// groupParamsChain returns the group params of an API and its parents (outermost first)
+func (a *API) groupParamsChain() []*groupParams {
+	chain := make([]*groupParams, 0)
+	for api := a; api != nil; api = api.parent {
+		if api.groupParams != nil {
+			chain = append([]*groupParams{api.groupParams}, chain...)
+		}
+	}
+	return chain
+}
+
+// unmarshalGroupParams parses every group params type in chain from a request
+func unmarshalGroupParams(request *http.Request, chain []*groupParams) ([]any, error) {
+	values := make([]any, 0, len(chain))
+	for _, group := range chain {
+		value := reflect.New(group.paramType)
+		if err := UnmarshalParams(request, value.Interface()); err != nil {
+			return nil, err
+		}
+		values = append(values, value.Interface())
+	}
+	return values, nil
+}
+
+// withGroupParameters returns the paths with the group's parameters added to the path-level parameters
+func (g *groupParams) withGroupParameters(paths map[string]Path) map[string]Path {
+	for path, obj := range paths {
+		obj.Parameters = append(append([]Parameter{}, g.parameters...), obj.Parameters...)
+		paths[path] = obj
+	}
+	return paths
+}
+
+// groupRouteContext is the RouteContext of a request to a route with group params
+type groupRouteContext struct {
+	*routeContext
+	params []any
+}
+
+// GroupParams returns the group params parsed for the request (outermost group first)
+func (r *groupRouteContext) GroupParams() []any {
+	return r.params
+}
+
+// routeContext returns the RouteContext of the request being written
+func (w *httpResponseWriter) routeContext() RouteContext {
+	if w.groupParams == nil {
+		return w.route.context
+	}
+	return &groupRouteContext{
+		routeContext: w.route.context,
+		params:       w.groupParams,
+	}
+}
+
+// GetGroupParams returns the Params parsed by the group (see WithGroupParams) of the route matched by a request
+func GetGroupParams[Params any](ctx RouteContext) (*Params, bool) {
+	groupCtx, ok := ctx.(groupParamsContext)
+	if !ok {
+		return nil, false
+	}
+	params := groupCtx.GroupParams()
+	for i := len(params) - 1; i >= 0; i-- {
+		if p, ok := params[i].(*Params); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
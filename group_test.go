@@ -0,0 +1,114 @@
+package chimera_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestTenantParams struct {
+	TenantID int `param:"tenantID,in=path"`
+}
+
+type TestProjectParams struct {
+	ProjectID string `param:"projectID,in=path"`
+}
+
+type TestTenantUserParams struct {
+	TenantID int    `param:"tenantID,in=path"`
+	UserID   string `param:"userID,in=path"`
+}
+
+func TestGroupParams(t *testing.T) {
+	api := chimera.NewAPI()
+	tenants := api.Group("/tenants/{tenantID}", chimera.WithGroupParams[TestTenantParams]())
+	projects := tenants.Group("/projects/{projectID}", chimera.WithGroupParams[TestProjectParams]())
+
+	// existing groups are reused but cant be reconfigured
+	assert.Same(t, projects, tenants.Group("/projects/{projectID}"))
+	assert.PanicsWithValue(t, "chimera: group /projects/{projectID} already exists, its options can only be set when it is created", func() {
+		tenants.Group("/projects/{projectID}", chimera.WithGroupParams[TestProjectParams]())
+	})
+
+	calls := 0
+	tenantID := 0
+	tenants.Use(func(req *http.Request, ctx chimera.RouteContext, next chimera.NextFunc) (chimera.ResponseWriter, error) {
+		calls++
+		params, ok := chimera.GetGroupParams[TestTenantParams](ctx)
+		assert.True(t, ok)
+		tenantID = params.TenantID
+		return next(req)
+	})
+	projectID := ""
+	projects.Use(func(req *http.Request, ctx chimera.RouteContext, next chimera.NextFunc) (chimera.ResponseWriter, error) {
+		params, ok := chimera.GetGroupParams[TestProjectParams](ctx)
+		assert.True(t, ok)
+		projectID = params.ProjectID
+		// parent group params are available to nested groups
+		tenant, ok := chimera.GetGroupParams[TestTenantParams](ctx)
+		assert.True(t, ok)
+		assert.Equal(t, tenantID, tenant.TenantID)
+		return next(req)
+	})
+	chimera.Get(tenants, "/users/{userID}", func(req *chimera.NoBodyRequest[TestTenantUserParams]) (*chimera.EmptyResponse, error) {
+		assert.Equal(t, 5, req.Params.TenantID)
+		assert.Equal(t, "abc", req.Params.UserID)
+		return nil, nil
+	})
+	chimera.Get(projects, "/", func(req *chimera.NoBodyRequest[chimera.Nil]) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tenants/5/users/abc", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 5, tenantID)
+
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tenants/6/projects/p1/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 6, tenantID)
+	assert.Equal(t, "p1", projectID)
+
+	// invalid group params fail before any middleware is called
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tenants/x/users/abc", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"tenantID"`)
+	assert.Equal(t, 2, calls)
+
+	// routes outside of a group have no group params
+	api.Use(func(req *http.Request, ctx chimera.RouteContext, next chimera.NextFunc) (chimera.ResponseWriter, error) {
+		_, ok := chimera.GetGroupParams[TestTenantParams](ctx)
+		assert.Equal(t, ctx.Path() != "/health", ok)
+		return next(req)
+	})
+	chimera.Get(api, "/health", func(req *chimera.NoBodyRequest[chimera.Nil]) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// group params are documented as path-level parameters
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	spec := chimera.OpenAPI{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	names := func(params []chimera.Parameter) []string {
+		n := make([]string, 0)
+		for _, p := range params {
+			n = append(n, p.Name)
+		}
+		return n
+	}
+	assert.Equal(t, []string{"tenantID"}, names(spec.Paths["/tenants/{tenantID}/users/{userID}"].Parameters))
+	assert.Equal(t, []string{"tenantID", "projectID"}, names(spec.Paths["/tenants/{tenantID}/projects/{projectID}/"].Parameters))
+	assert.Equal(t, "integer", string(spec.Paths["/tenants/{tenantID}/users/{userID}"].Parameters[0].Schema.Type))
+	assert.Empty(t, spec.Paths["/health"].Parameters)
+}
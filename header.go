@@ -37,6 +37,34 @@ func unmarshalHeaderParam(param []string, tag *ParamStructTag, addr reflect.Valu
 	return unmarshalStringParam(param[0], tag, addr)
 }
 
+// unmarshalResponseHeaderParam is the inverse of marshalHeaderParam and converts a response header to a value
+func unmarshalResponseHeaderParam(param []string, tag *ParamStructTag, addr reflect.Value) error {
+	addr = fixPointer(addr)
+	if u, ok := addr.Interface().(HeaderParamUnmarshaler); ok {
+		return u.UnmarshalHeaderParam(param, *tag)
+	}
+	if tag.schemaType == interfaceType {
+		return fmt.Errorf("chimera: header parameter %s does not implement HeaderParamUnmarshaler", tag.Name)
+	}
+	return unmarshalStringParam(param[0], tag, addr)
+}
+
+// marshalRequestHeaderParam is the inverse of unmarshalHeaderParam and converts a value to a request header
+func marshalRequestHeaderParam(tag *ParamStructTag, addr reflect.Value) (http.Header, error) {
+	if tag.schemaType == interfaceType {
+		if m, ok := addr.Interface().(HeaderParamMarshaler); ok {
+			return m.MarshalHeaderParam(*tag)
+		}
+		return nil, fmt.Errorf("chimera: header parameter %s does not implement HeaderParamMarshaler", tag.Name)
+	}
+	if tag.schemaType == mapType {
+		return marshalMapToHeader(tag, addr), nil
+	}
+	return http.Header{
+		tag.Name: []string{marshalStringParam(tag, addr)},
+	}, nil
+}
+
 // marshalHeaderParam converts a value to a http.Header using the options in tag
 func marshalHeaderParam(tag *ParamStructTag, addr reflect.Value) (http.Header, error) {
 	addr = fixPointer(addr)
@@ -45,16 +73,26 @@ func marshalHeaderParam(tag *ParamStructTag, addr reflect.Value) (http.Header, e
 		return addr.Interface().(HeaderParamMarshaler).MarshalHeaderParam(*tag)
 	case primitiveType:
 		return http.Header{
-			tag.Name: []string{marshalPrimitiveToString(addr)},
+			tag.Name: []string{encodeParamString(addr, tag.Layout)},
 		}, nil
 	case sliceType:
 		return http.Header{
-			tag.Name: []string{marshalSliceToString(addr)},
+			tag.Name: []string{marshalSliceToString(addr, tag)},
 		}, nil
 	case structType:
 		return http.Header{
 			tag.Name: []string{marshalStructToString(addr, tag)},
 		}, nil
+	case mapType:
+		return marshalMapToHeader(tag, addr), nil
+	case encodedType:
+		value, err := marshalEncodedParam(tag, addr)
+		if err != nil {
+			return nil, err
+		}
+		return http.Header{
+			tag.Name: []string{value},
+		}, nil
 	}
 	return nil, nil
 }
@@ -65,14 +103,14 @@ func marshalPrimitiveToString(addr reflect.Value) string {
 }
 
 // marshalSliceToString converts a slice/array value to a string
-func marshalSliceToString(addr reflect.Value) string {
+func marshalSliceToString(addr reflect.Value, tag *ParamStructTag) string {
 	value := ""
 	addr = addr.Elem()
 	for i := 0; i < addr.Len(); i++ {
 		if i != 0 {
 			value += ","
 		}
-		value += marshalPrimitiveToString(addr.Index(i).Addr())
+		value += encodeParamString(addr.Index(i).Addr(), tag.Layout)
 	}
 	return value
 }
@@ -81,12 +119,15 @@ func marshalSliceToString(addr reflect.Value) string {
 func marshalStructToString(addr reflect.Value, tag *ParamStructTag) string {
 	values := make([]string, 0)
 	for name, prop := range tag.propMap {
-		f := addr.Elem().Field(prop.fieldIndex)
+		f, ok := lookupParamField(addr.Elem(), prop.index)
+		if !ok {
+			continue
+		}
 		if f.Type().Kind() == reflect.Pointer {
 			f = fixPointer(f)
 		}
 		v := name + tag.valueDelim
-		v += marshalPrimitiveToString(f.Addr())
+		v += encodeParamString(f.Addr(), tag.Layout)
 		values = append(values, v)
 	}
 	return strings.Join(values, tag.delim)
@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"reflect"
-	"strings"
 
 	"github.com/invopop/jsonschema"
 )
@@ -16,6 +15,10 @@ var (
 	_ ResponseWriter = new(JSONResponse[Nil, Nil])
 	_ RequestReader  = new(JSON[Nil, Nil])
 	_ ResponseWriter = new(JSON[Nil, Nil])
+	_ RequestWriter  = new(JSONRequest[Nil, Nil])
+	_ ResponseReader = new(JSONResponse[Nil, Nil])
+	_ RequestWriter  = new(JSON[Nil, Nil])
+	_ ResponseReader = new(JSON[Nil, Nil])
 )
 
 // JSONRequest[Body, Params any] is a request type that decodes json request bodies to a
@@ -34,6 +37,11 @@ func (r *JSONRequest[Body, Params]) Context() context.Context {
 	return nil
 }
 
+// httpRequest returns the original http.Request (see Callback.URL)
+func (r *JSONRequest[Body, Params]) httpRequest() *http.Request {
+	return r.request
+}
+
 func readJSONRequest[Body, Params any](req *http.Request, body *Body, params *Params) error {
 	defer req.Body.Close()
 	b, err := io.ReadAll(req.Body)
@@ -65,59 +73,43 @@ func (r *JSONRequest[Body, Params]) ReadRequest(req *http.Request) error {
 	return readJSONRequest(req, &r.Body, &r.Params)
 }
 
-// standardizedSchemas basically tries to convert all jsonschema.Schema objects to be mapped
-// in defs and then replace them with $refs
-func standardizedSchemas(schema *jsonschema.Schema, defs map[string]jsonschema.Schema) {
-	if schema == nil {
-		return
-	}
-	schema.ID = ""
-	schema.Version = ""
-	standardizedSchemas(schema.AdditionalProperties, defs)
-	standardizedSchemas(schema.Contains, defs)
-	standardizedSchemas(schema.ContentSchema, defs)
-	standardizedSchemas(schema.Else, defs)
-	standardizedSchemas(schema.If, defs)
-	standardizedSchemas(schema.Items, defs)
-	standardizedSchemas(schema.Not, defs)
-	standardizedSchemas(schema.PropertyNames, defs)
-	standardizedSchemas(schema.Then, defs)
-	for _, s := range schema.AllOf {
-		standardizedSchemas(s, defs)
-	}
-	for _, s := range schema.AnyOf {
-		standardizedSchemas(s, defs)
-	}
-	for _, s := range schema.OneOf {
-		standardizedSchemas(s, defs)
+func writeJSONRequest[Body, Params any](req *http.Request, body *Body, params *Params) error {
+	if _, ok := any(body).(*Nil); !ok {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		setRequestBody(req, "application/json", b)
 	}
-	for _, s := range schema.DependentSchemas {
-		standardizedSchemas(s, defs)
+
+	if _, ok := any(params).(*Nil); !ok {
+		return MarshalRequestParams(req, params)
 	}
-	for _, s := range schema.PatternProperties {
-		standardizedSchemas(s, defs)
+	return nil
+}
+
+// WriteRequest writes the Body field to the request using json.Marshal and the Params field using MarshalRequestParams
+func (r *JSONRequest[Body, Params]) WriteRequest(req *http.Request) error {
+	return writeJSONRequest(req, &r.Body, &r.Params)
+}
+
+func readJSONResponse[Body, Params any](resp *http.Response, body *Body, params *Params) error {
+	b, err := readResponseBody(resp)
+	if err != nil {
+		return err
 	}
-	for k, s := range schema.Definitions {
-		standardizedSchemas(s, defs)
-		if _, ok := defs[k]; ok {
-			split := strings.Split(s.ID.String(), "/")
-			for i, p := range split {
-				if i != len(split)-1 {
-					k = p + "_" + k
-				}
-			}
+
+	if _, ok := any(body).(*Nil); !ok && len(b) > 0 {
+		err = json.Unmarshal(b, body)
+		if err != nil {
+			return err
 		}
-		defs[k] = *s
-	}
-	schema.Definitions = nil
-	for p := schema.Properties.Oldest(); p != nil; p = p.Next() {
-		standardizedSchemas(p.Value, defs)
 	}
-	if strings.HasPrefix(schema.Ref, "#/$defs/") {
-		name, _ := strings.CutPrefix(schema.Ref, "#/$defs/")
-		schema.Ref = "#/components/schemas/" + name
+
+	if _, ok := any(params).(*Nil); !ok {
+		return UnmarshalResponseParams(resp, params)
 	}
-	schema.ID = ""
+	return nil
 }
 
 func jsonRequestSpec[Body, Params any](schema *RequestSpec) {
@@ -126,13 +118,14 @@ func jsonRequestSpec[Body, Params any](schema *RequestSpec) {
 	}
 
 	if bType != reflect.TypeOf(Nil{}) {
-		s := (&jsonschema.Reflector{
+		s := newReflector(jsonschema.Reflector{
 			// ExpandedStruct: bType.Kind() == reflect.Struct,
 		}).Reflect(new(Body))
 		schema.RequestBody = &RequestBody{
 			Content: map[string]MediaType{
 				"application/json": {
-					Schema: s,
+					Schema:   s,
+					Examples: bodyExamples[Body](),
 				},
 			},
 			Required: reflect.TypeOf(*new(Body)).Kind() != reflect.Pointer,
@@ -156,10 +149,11 @@ func jsonResponsesSpec[Body, Params any](schema Responses) {
 	if bType != reflect.TypeOf(Nil{}) {
 		response.Content = map[string]MediaType{
 			"application/json": {
-				Schema: (&jsonschema.Reflector{
+				Schema: newReflector(jsonschema.Reflector{
 					// ExpandedStruct: bType.Kind() == reflect.Struct,
 					// DoNotReference: true,
 				}).Reflect(new(Body)),
+				Examples: bodyExamples[Body](),
 			},
 		}
 	}
@@ -220,16 +214,13 @@ func (r *JSONResponse[Body, Params]) OpenAPIResponsesSpec() Responses {
 // WriteHead writes header for this response object
 func (r *JSONResponse[Body, Params]) WriteHead(head *ResponseHead) error {
 	head.Headers.Set("Content-Type", "application/json")
-	h, err := MarshalParams(&r.Params)
-	if err != nil {
-		return err
-	}
-	for k, v := range h {
-		for _, x := range v {
-			head.Headers.Add(k, x)
-		}
-	}
-	return nil
+	return head.MarshalParams(&r.Params)
+}
+
+// ReadResponse reads the response body into the Body field using json.Unmarshal
+// and the response headers into the Params field using UnmarshalResponseParams
+func (r *JSONResponse[Body, Params]) ReadResponse(resp *http.Response) error {
+	return readJSONResponse(resp, &r.Body, &r.Params)
 }
 
 // NewJSONResponse creates a JSONResponse from body and params
@@ -257,6 +248,11 @@ func (r *JSON[Body, Params]) Context() context.Context {
 	return nil
 }
 
+// httpRequest returns the original http.Request (see Callback.URL)
+func (r *JSON[Body, Params]) httpRequest() *http.Request {
+	return r.request
+}
+
 // ReadRequest reads the body of an http request and assigns it to the Body field using json.Unmarshal
 // This function also reads the parameters using UnmarshalParams and assigns it to the Params field.
 // NOTE: the body of the request is closed after this function is run.
@@ -265,6 +261,17 @@ func (r *JSON[Body, Params]) ReadRequest(req *http.Request) error {
 	return readJSONRequest(req, &r.Body, &r.Params)
 }
 
+// WriteRequest writes the Body field to the request using json.Marshal and the Params field using MarshalRequestParams
+func (r *JSON[Body, Params]) WriteRequest(req *http.Request) error {
+	return writeJSONRequest(req, &r.Body, &r.Params)
+}
+
+// ReadResponse reads the response body into the Body field using json.Unmarshal
+// and the response headers into the Params field using UnmarshalResponseParams
+func (r *JSON[Body, Params]) ReadResponse(resp *http.Response) error {
+	return readJSONResponse(resp, &r.Body, &r.Params)
+}
+
 // OpenAPIRequestSpec returns the Request definition of a JSON request using "invopop/jsonschema"
 func (r *JSON[Body, Params]) OpenAPIRequestSpec() RequestSpec {
 	schema := RequestSpec{}
@@ -292,14 +299,5 @@ func (r *JSON[Body, Params]) OpenAPIResponsesSpec() Responses {
 // WriteHead writes header for this response object
 func (r *JSON[Body, Params]) WriteHead(head *ResponseHead) error {
 	head.Headers.Set("Content-Type", "application/json")
-	h, err := MarshalParams(&r.Params)
-	if err != nil {
-		return err
-	}
-	for k, v := range h {
-		for _, x := range v {
-			head.Headers.Add(k, x)
-		}
-	}
-	return nil
+	return head.MarshalParams(&r.Params)
 }
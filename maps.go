@@ -0,0 +1,203 @@
+package chimera
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// isPrimitiveKind checks if a kind can be decoded from a single string
+func isPrimitiveKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// initMap validates a map param (map[string]T or map[string][]T) and sets up its schema, addr is a pointer to the field
+func (p *ParamStructTag) initMap(addr reflect.Value) {
+	mType := addr.Elem().Type()
+	eType := mType.Elem()
+	if eType.Kind() == reflect.Slice && !isScalarType(eType) {
+		eType = eType.Elem()
+	}
+	if mType.Key().Kind() != reflect.String || !isScalarType(eType) {
+		panic("chimera: map param " + p.Name + " must be a map[string]T or map[string][]T of primitives")
+	}
+	switch p.In {
+	case QueryIn:
+		if p.Style != DeepObjectStyle && !(p.Style == FormStyle && p.Explode) {
+			panic("chimera: map query param " + p.Name + " must use style=deepObject or an exploded form style")
+		}
+		p.Explode = true
+	case HeaderIn:
+		if !strings.HasSuffix(p.Name, "*") {
+			panic("chimera: map header param " + p.Name + " must be a prefix (i.e. X-Meta-*)")
+		}
+		p.prefix = strings.TrimSuffix(p.Name, "*")
+		if p.Description == "" {
+			p.Description = "headers starting with " + p.prefix
+		}
+	default:
+		panic("chimera: map params are only supported in query and header params (" + p.Name + ")")
+	}
+	p.schemaType = mapType
+	p.schema = newParamReflector(p.Layout, jsonschema.Reflector{
+		DoNotReference: true,
+		FieldNameTag:   "prop",
+	}).Reflect(addr.Interface())
+}
+
+// setMapEntry decodes the values of a map entry and adds it to the map at addr
+func setMapEntry(key string, values []string, tag *ParamStructTag, addr reflect.Value) error {
+	m := addr.Elem()
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	eType := m.Type().Elem()
+	if eType.Kind() != reflect.Slice || isScalarType(eType) {
+		val, err := decodeParamString(values[0], eType, tag.Layout)
+		if err != nil {
+			return NewInvalidParamError(marshalIn(tag.In), tag.Name, values[0])
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(m.Type().Key()), val)
+		return nil
+	}
+	slice := reflect.MakeSlice(eType, 0, len(values))
+	for _, v := range values {
+		val, err := decodeParamString(v, eType.Elem(), tag.Layout)
+		if err != nil {
+			return NewInvalidParamError(marshalIn(tag.In), tag.Name, v)
+		}
+		slice = reflect.Append(slice, val)
+	}
+	m.SetMapIndex(reflect.ValueOf(key).Convert(m.Type().Key()), slice)
+	return nil
+}
+
+// isReserved checks if a query key belongs to another param (see ParamStructTag.reserved)
+func (tag *ParamStructTag) isReserved(key string) bool {
+	if _, ok := tag.reserved[key]; ok {
+		return true
+	}
+	for _, prefix := range tag.reservedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unmarshalMapFromQuery reads every query value that belongs to a map param (name[key] for deepObject,
+// anything that isnt another param for exploded forms)
+func unmarshalMapFromQuery(param url.Values, tag *ParamStructTag, addr reflect.Value) error {
+	found := false
+	for name, values := range param {
+		if len(values) == 0 {
+			continue
+		}
+		key := name
+		if tag.Style == DeepObjectStyle {
+			inner, ok := strings.CutPrefix(name, tag.Name+"[")
+			if !ok || !strings.HasSuffix(inner, "]") {
+				continue
+			}
+			key = strings.TrimSuffix(inner, "]")
+		} else if tag.isReserved(name) {
+			continue
+		}
+		found = true
+		if err := setMapEntry(key, values, tag, addr); err != nil {
+			return err
+		}
+	}
+	if !found && tag.Required {
+		return NewRequiredParamError("query", tag.Name)
+	}
+	return nil
+}
+
+// unmarshalMapFromHeader reads every header that starts with the prefix of a map param
+func unmarshalMapFromHeader(header http.Header, tag *ParamStructTag, addr reflect.Value) error {
+	found := false
+	prefix := strings.ToLower(tag.prefix)
+	for name, values := range header {
+		if len(values) == 0 || len(name) <= len(prefix) || strings.ToLower(name[:len(prefix)]) != prefix {
+			continue
+		}
+		if eType := addr.Elem().Type().Elem(); eType.Kind() == reflect.Slice && !isScalarType(eType) {
+			// multiple values can be sent as separate headers or a comma separated list
+			split := make([]string, 0, len(values))
+			for _, v := range values {
+				for _, x := range strings.Split(v, ",") {
+					split = append(split, strings.TrimSpace(x))
+				}
+			}
+			values = split
+		}
+		found = true
+		if err := setMapEntry(name[len(prefix):], values, tag, addr); err != nil {
+			return err
+		}
+	}
+	if !found && tag.Required {
+		return NewRequiredParamError("header", tag.Name)
+	}
+	return nil
+}
+
+// mapEntries gets the keys (sorted) and string values of a map param
+func mapEntries(tag *ParamStructTag, addr reflect.Value) ([]string, [][]string) {
+	m := addr.Elem()
+	keys := make([]string, 0, m.Len())
+	for _, k := range m.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	values := make([][]string, len(keys))
+	for i, key := range keys {
+		v := m.MapIndex(reflect.ValueOf(key).Convert(m.Type().Key()))
+		if v.Kind() == reflect.Slice && !isScalarType(v.Type()) {
+			for j := 0; j < v.Len(); j++ {
+				values[i] = append(values[i], encodeParamString(v.Index(j).Addr(), tag.Layout))
+			}
+		} else {
+			ptr := reflect.New(v.Type())
+			ptr.Elem().Set(v)
+			values[i] = []string{encodeParamString(ptr, tag.Layout)}
+		}
+	}
+	return keys, values
+}
+
+// marshalMapToQuery is the inverse of unmarshalMapFromQuery
+func marshalMapToQuery(tag *ParamStructTag, addr reflect.Value) url.Values {
+	query := make(url.Values)
+	keys, values := mapEntries(tag, addr)
+	for i, key := range keys {
+		if tag.Style == DeepObjectStyle {
+			key = tag.Name + "[" + key + "]"
+		}
+		query[key] = values[i]
+	}
+	return query
+}
+
+// marshalMapToHeader is the inverse of unmarshalMapFromHeader
+func marshalMapToHeader(tag *ParamStructTag, addr reflect.Value) http.Header {
+	header := make(http.Header)
+	keys, values := mapEntries(tag, addr)
+	for i, key := range keys {
+		for _, v := range values[i] {
+			header.Add(tag.prefix+key, v)
+		}
+	}
+	return header
+}
@@ -0,0 +1,125 @@
+package chimera_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestMapParams struct {
+	Filter map[string]string   `param:"filter,in=query"`
+	Tags   map[string][]int    `param:"tags,in=query,style=deepObject"`
+	Meta   map[string]string   `param:"X-Meta-*,in=header"`
+	Limits map[string][]uint16 `param:"X-Limit-*,in=header"`
+}
+
+type TestExplodedMapParams struct {
+	Limit int               `param:"limit,in=query"`
+	Rest  map[string]string `param:"rest,in=query,style=form,explode"`
+}
+
+type TestExplodedMapNestedParams struct {
+	Q    TestSearchQuery                     `param:"q,in=query,nested=brackets"`
+	D    TestSearchQuery                     `param:"d,in=query,nested=dots"`
+	Rest chimera.Optional[map[string]string] `param:"rest,in=query,style=form,explode"`
+}
+
+func TestMapParamBinding(t *testing.T) {
+	api := chimera.NewAPI()
+	route := chimera.Get(api, "/maps", func(req *chimera.NoBodyRequest[TestMapParams]) (*chimera.NoBodyResponse[TestMapParams], error) {
+		assert.Equal(t, TestMapParams{
+			Filter: map[string]string{"status": "open", "owner": "me"},
+			Tags:   map[string][]int{"a": {1, 2}},
+			Meta:   map[string]string{"Request-Id": "abc"},
+			Limits: map[string][]uint16{"Rate": {1, 2, 3}},
+		}, req.Params)
+		return &chimera.NoBodyResponse[TestMapParams]{Params: req.Params}, nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/maps?filter[status]=open&filter[owner]=me&tags[a]=1&tags[a]=2&other=x", nil)
+	req.Header.Set("X-Meta-Request-Id", "abc")
+	req.Header.Add("X-Limit-Rate", "1, 2")
+	req.Header.Add("X-Limit-Rate", "3")
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "abc", w.Header().Get("X-Meta-Request-Id"))
+	assert.Equal(t, []string{"1", "2", "3"}, w.Header().Values("X-Limit-Rate"))
+
+	// responses can be read back
+	params := TestMapParams{}
+	assert.NoError(t, chimera.UnmarshalResponseParams(w.Result(), &params))
+	assert.Equal(t, map[string]string{"Request-Id": "abc"}, params.Meta)
+
+	// invalid values are improperly formatted params
+	req = httptest.NewRequest(http.MethodGet, "/maps?tags[a]=x", nil)
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"tags"`)
+
+	// maps are described as objects with additionalProperties
+	for _, param := range route.OpenAPIOperationSpec().Parameters {
+		assert.Equal(t, "object", string(param.Schema.Type))
+		assert.NotNil(t, param.Schema.AdditionalProperties)
+		switch param.Name {
+		case "filter", "tags":
+			assert.Equal(t, "deepObject", param.Style)
+			assert.True(t, param.Explode)
+		}
+	}
+
+	// exploded forms get every query value that doesnt belong to another param
+	chimera.Get(api, "/exploded", func(req *chimera.NoBodyRequest[TestExplodedMapParams]) (*chimera.EmptyResponse, error) {
+		assert.Equal(t, 10, req.Params.Limit)
+		assert.Equal(t, map[string]string{"status": "open"}, req.Params.Rest)
+		return nil, nil
+	})
+	req = httptest.NewRequest(http.MethodGet, "/exploded?limit=10&status=open", nil)
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	type invalidHeaderMap struct {
+		Meta map[string]string `param:"X-Meta,in=header"`
+	}
+	assert.Panics(t, func() {
+		chimera.Get(api, "/invalid", func(req *chimera.NoBodyRequest[invalidHeaderMap]) (*chimera.EmptyResponse, error) {
+			return nil, nil
+		})
+	})
+	type invalidMapValue struct {
+		Meta map[string]TestTraceContext `param:"meta,in=query"`
+	}
+	assert.Panics(t, func() {
+		chimera.Get(api, "/invalid", func(req *chimera.NoBodyRequest[invalidMapValue]) (*chimera.EmptyResponse, error) {
+			return nil, nil
+		})
+	})
+}
+
+func TestExplodedMapParamNestedSiblings(t *testing.T) {
+	api := chimera.NewAPI()
+	var rest chimera.Optional[map[string]string]
+	chimera.Get(api, "/search", func(req *chimera.NoBodyRequest[TestExplodedMapNestedParams]) (*chimera.EmptyResponse, error) {
+		assert.Equal(t, &TestSearchRange{From: 1}, req.Params.Q.Range)
+		assert.Equal(t, []string{"a"}, req.Params.D.Tags)
+		rest = req.Params.Rest
+		return nil, nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/search?q[range][from]=1&d.tags=a&status=open", nil)
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, rest.IsSet())
+	assert.Equal(t, map[string]string{"status": "open"}, rest.Value())
+
+	// keys of the nested params dont belong to the map, so it is absent without keys of its own
+	req = httptest.NewRequest(http.MethodGet, "/search?q[range][from]=1&d.tags=a", nil)
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, rest.IsSet())
+}
@@ -111,6 +111,13 @@ func HTTPMiddleware(middleware func(http.Handler) http.Handler) MiddlewareFunc {
 				Headers: w.Header(),
 				StatusCode: ctx.DefaultResponseCode(),
 			}
+			rc, ok := ctx.(*routeContext)
+			if g, isGroup := ctx.(*groupRouteContext); isGroup {
+				rc, ok = g.routeContext, true
+			}
+			if ok && rc.api != nil {
+				head.cookieKeys = rc.api.cookieKeyRing()
+			}
 			err = resp.WriteHead(&head)
 			if err != nil {
 				if unchanged && writer.statusCode < 1 {
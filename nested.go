@@ -0,0 +1,327 @@
+package chimera
+
+import (
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	// NestedBrackets is the bracket syntax for nested query params (i.e. q[range][from]=1&q[tags][]=a)
+	NestedBrackets = "brackets"
+	// NestedDots is the dotted syntax for nested query params (i.e. q.range.from=1&q.tags=a)
+	NestedDots = "dots"
+
+	// maxNestedIndex is the largest slice index accepted by nested query params (i.e. q[sort][1000][field]),
+	// larger indexes are invalid params since slices are grown to fit the index
+	maxNestedIndex = 1000
+)
+
+var (
+	nestedFieldCache sync.Map
+)
+
+// nestedFields gets the index paths of the fields of a struct type by prop name (using the same names as the schema),
+// embedded structs are flattened like the props of struct params (see paramPropFields)
+func nestedFields(t reflect.Type) map[string][]int {
+	if fields, ok := nestedFieldCache.Load(t); ok {
+		return fields.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	for _, prop := range paramPropFields(t) {
+		field := t.FieldByIndex(prop.index)
+		if !field.IsExported() || prop.name == "-" || strings.Split(field.Tag.Get("jsonschema"), ",")[0] == "-" {
+			continue
+		}
+		name := prop.name
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = prop.index
+	}
+	nestedFieldCache.Store(t, fields)
+	return fields
+}
+
+// checkNestedType ensures every leaf of a nested param can be decoded from a string
+func checkNestedType(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for ; t.Kind() == reflect.Pointer; t = t.Elem() {
+	}
+	if seen[t] || isScalarType(t) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		seen[t] = true
+		for _, index := range nestedFields(t) {
+			if !checkNestedType(t.FieldByIndex(index).Type, seen) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		return checkNestedType(t.Elem(), seen)
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && checkNestedType(t.Elem(), seen)
+	}
+	return false
+}
+
+// initNested validates a nested query param and sets up its (flattened) schema, addr is a pointer to the field
+func (p *ParamStructTag) initNested(addr reflect.Value) {
+	if p.Nested != NestedBrackets && p.Nested != NestedDots {
+		panic("chimera: nested must be " + NestedBrackets + " or " + NestedDots + " for param " + p.Name)
+	}
+	if p.In != QueryIn || p.Style != DeepObjectStyle {
+		panic("chimera: nested params must be query params with style=deepObject (" + p.Name + ")")
+	}
+	kind := addr.Elem().Kind()
+	if (kind != reflect.Struct && kind != reflect.Slice && kind != reflect.Map) || !checkNestedType(addr.Elem().Type(), make(map[reflect.Type]bool)) {
+		panic("chimera: nested param " + p.Name + " must be a struct, slice or map of primitives, structs, slices and maps")
+	}
+	p.Explode = true
+	p.schemaType = nestedType
+	s := newParamReflector(p.Layout, jsonschema.Reflector{FieldNameTag: "prop"}).Reflect(addr.Interface())
+	properties := make(map[string]*jsonschema.Schema)
+	flattenQuerySchemas(s, properties, s.Definitions, "^"+regexp.QuoteMeta(p.Name), p.Nested, make(map[string]bool))
+	p.schema = &jsonschema.Schema{
+		Type:                 "object",
+		PatternProperties:    properties,
+		AdditionalProperties: jsonschema.FalseSchema,
+	}
+}
+
+// flattenQuerySchemas converts the schema of a nested query param into patternProperties keyed by the paths of its leaves
+// (the same way flattenFormSchemas does for forms), recursive types match anything below the point they repeat
+func flattenQuerySchemas(schema *jsonschema.Schema, properties map[string]*jsonschema.Schema, refs jsonschema.Definitions, prefix, nested string, seen map[string]bool) {
+	if schema.Ref != "" {
+		name := schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+		def, ok := refs[name]
+		if !ok || seen[name] {
+			properties[prefix+".*$"] = &jsonschema.Schema{}
+			return
+		}
+		seen[name] = true
+		defer delete(seen, name)
+		schema = def
+	}
+	switch schema.Type {
+	case "object":
+		if schema.Properties != nil && schema.Properties.Len() > 0 {
+			for p := schema.Properties.Oldest(); p != nil; p = p.Next() {
+				flattenQuerySchemas(p.Value, properties, refs, prefix+nestedKeyPattern(regexp.QuoteMeta(p.Key), nested), nested, seen)
+			}
+		} else if schema.AdditionalProperties != nil {
+			flattenQuerySchemas(schema.AdditionalProperties, properties, refs, prefix+nestedKeyPattern("[^\\[\\]\\.]+", nested), nested, seen)
+		}
+	case "array":
+		if schema.Items.Type == "object" || schema.Items.Type == "array" || schema.Items.Ref != "" {
+			flattenQuerySchemas(schema.Items, properties, refs, prefix+"\\[\\d+\\]", nested, seen)
+		} else {
+			flattenQuerySchemas(schema.Items, properties, refs, prefix+"(\\[\\d*\\])?", nested, seen)
+		}
+	default:
+		properties[prefix+"$"] = schema
+	}
+}
+
+// nestedKeyPattern is the pattern of a key in a nested param
+func nestedKeyPattern(key, nested string) string {
+	if nested == NestedDots {
+		return "\\." + key
+	}
+	return "\\[" + key + "\\]"
+}
+
+// nestedPath splits the part of a query key after the param name into its path (i.e. [a][b][] and .a.b[] are both [a b ""]),
+// ok is false if the key is not formatted using the nested syntax
+func nestedPath(key, nested string) ([]string, bool) {
+	path := make([]string, 0)
+	for key != "" {
+		if key[0] == '[' {
+			end := strings.IndexByte(key, ']')
+			if end < 0 {
+				return nil, false
+			}
+			path = append(path, key[1:end])
+			key = key[end+1:]
+			continue
+		}
+		if nested != NestedDots || key[0] != '.' {
+			return nil, false
+		}
+		end := strings.IndexAny(key[1:], ".[")
+		if end < 0 {
+			end = len(key) - 1
+		}
+		path = append(path, key[1:end+1])
+		key = key[end+1:]
+	}
+	return path, true
+}
+
+// setNestedValue sets the value at path below addr (a pointer), slices of primitives get every value
+func setNestedValue(addr reflect.Value, path []string, values []string, tag *ParamStructTag) error {
+	// unlike fixPointer, existing pointers are kept since a value can be set by multiple keys
+	for addr.Elem().Kind() == reflect.Pointer {
+		if addr.Elem().IsNil() {
+			addr.Elem().Set(reflect.New(addr.Elem().Type().Elem()))
+		}
+		addr = addr.Elem()
+	}
+	value := addr.Elem()
+	kind := value.Kind()
+	if _, ok := scalarFor(value.Type()); ok {
+		kind = reflect.String
+	}
+	switch kind {
+	case reflect.Struct:
+		if len(path) == 0 {
+			return nil
+		}
+		index, ok := nestedFields(value.Type())[path[0]]
+		if !ok {
+			return nil
+		}
+		return setNestedValue(paramField(value, index).Addr(), path[1:], values, tag)
+	case reflect.Map:
+		if len(path) == 0 {
+			return nil
+		}
+		if value.IsNil() {
+			value.Set(reflect.MakeMap(value.Type()))
+		}
+		key := reflect.ValueOf(path[0]).Convert(value.Type().Key())
+		elem := reflect.New(value.Type().Elem())
+		if existing := value.MapIndex(key); existing.IsValid() {
+			elem.Elem().Set(existing)
+		}
+		if err := setNestedValue(elem, path[1:], values, tag); err != nil {
+			return err
+		}
+		value.SetMapIndex(key, elem.Elem())
+		return nil
+	case reflect.Slice:
+		if len(path) == 0 || path[0] == "" {
+			for _, v := range values {
+				elem := reflect.New(value.Type().Elem())
+				if err := setNestedValue(elem, nil, []string{v}, tag); err != nil {
+					return err
+				}
+				value.Set(reflect.Append(value, elem.Elem()))
+			}
+			return nil
+		}
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i > maxNestedIndex {
+			return NewInvalidParamError("query", tag.Name, path[0])
+		}
+		if i >= value.Len() {
+			grown := reflect.MakeSlice(value.Type(), i+1, i+1)
+			reflect.Copy(grown, value)
+			value.Set(grown)
+		}
+		return setNestedValue(value.Index(i).Addr(), path[1:], values, tag)
+	}
+	if len(path) > 0 || len(values) == 0 {
+		return nil
+	}
+	v, err := decodeParamString(values[0], value.Type(), tag.Layout)
+	if err != nil {
+		return NewInvalidParamError("query", tag.Name, values[0])
+	}
+	value.Set(v)
+	return nil
+}
+
+// unmarshalNestedFromQuery unmarshals every query value that belongs to a nested param
+func unmarshalNestedFromQuery(param url.Values, tag *ParamStructTag, addr reflect.Value) error {
+	keys := make([]string, 0)
+	for key := range param {
+		if strings.HasPrefix(key, tag.Name) {
+			keys = append(keys, key)
+		}
+	}
+	// sorted so that indexes and errors are deterministic
+	sort.Strings(keys)
+	found := false
+	for _, key := range keys {
+		path, ok := nestedPath(key[len(tag.Name):], tag.Nested)
+		if !ok || len(param[key]) == 0 {
+			continue
+		}
+		found = true
+		if err := setNestedValue(addr, path, param[key], tag); err != nil {
+			return err
+		}
+	}
+	if !found && tag.Required {
+		return NewRequiredParamError("query", tag.Name)
+	}
+	return nil
+}
+
+// marshalNestedToQuery is the inverse of unmarshalNestedFromQuery
+func marshalNestedToQuery(tag *ParamStructTag, addr reflect.Value) url.Values {
+	values := make(url.Values)
+	marshalNestedValue(addr, tag.Name, tag, values)
+	return values
+}
+
+// marshalNestedValue adds the query values of the value at addr using key as the path so far, nil values are skipped
+func marshalNestedValue(addr reflect.Value, key string, tag *ParamStructTag, values url.Values) {
+	addr, ok := derefParam(addr)
+	if !ok {
+		return
+	}
+	value := addr.Elem()
+	if isScalarType(value.Type()) {
+		values.Add(key, encodeParamString(addr, tag.Layout))
+		return
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		for name, index := range nestedFields(value.Type()) {
+			if field, ok := lookupParamField(value, index); ok {
+				marshalNestedValue(field.Addr(), key+nestedKey(name, tag.Nested), tag, values)
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			elem := reflect.New(iter.Value().Type())
+			elem.Elem().Set(iter.Value())
+			marshalNestedValue(elem, key+nestedKey(iter.Key().String(), tag.Nested), tag, values)
+		}
+	case reflect.Slice:
+		eType := value.Type().Elem()
+		for ; eType.Kind() == reflect.Pointer; eType = eType.Elem() {
+		}
+		for i := 0; i < value.Len(); i++ {
+			if isScalarType(eType) {
+				// primitives are repeated (i.e. q[tags][]=a&q[tags][]=b or q.tags=a&q.tags=b)
+				sliceKey := key
+				if tag.Nested == NestedBrackets {
+					sliceKey += "[]"
+				}
+				marshalNestedValue(value.Index(i).Addr(), sliceKey, tag, values)
+			} else {
+				marshalNestedValue(value.Index(i).Addr(), key+"["+strconv.Itoa(i)+"]", tag, values)
+			}
+		}
+	}
+}
+
+// nestedKey formats a key of a nested param
+func nestedKey(key, nested string) string {
+	if nested == NestedDots {
+		return "." + key
+	}
+	return "[" + key + "]"
+}
@@ -0,0 +1,184 @@
+package chimera_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestSearchRange struct {
+	From int `prop:"from"`
+	To   int `prop:"to"`
+}
+
+type TestSearchSort struct {
+	Field string `prop:"field"`
+	Desc  bool   `prop:"desc"`
+}
+
+type TestSearchQuery struct {
+	Range *TestSearchRange   `prop:"range"`
+	Tags  []string           `prop:"tags"`
+	Sort  []TestSearchSort   `prop:"sort"`
+	Extra map[string][]int64 `prop:"extra"`
+}
+
+type TestNestedParams struct {
+	Q      TestSearchQuery  `param:"q,in=query,nested=brackets"`
+	Dotted TestSearchQuery  `param:"d,in=query,nested=dots"`
+	Sorts  []TestSearchSort `param:"sorts,in=query,nested=brackets"`
+}
+
+func TestNestedQueryParams(t *testing.T) {
+	expected := TestSearchQuery{
+		Range: &TestSearchRange{From: 1, To: 5},
+		Tags:  []string{"a", "b"},
+		Sort:  []TestSearchSort{{Field: "name"}, {Field: "age", Desc: true}},
+		Extra: map[string][]int64{"x": {1, 2}},
+	}
+	api := chimera.NewAPI()
+	route := chimera.Get(api, "/search", func(req *chimera.NoBodyRequest[TestNestedParams]) (*chimera.EmptyResponse, error) {
+		assert.Equal(t, expected, req.Params.Q)
+		assert.Equal(t, expected, req.Params.Dotted)
+		assert.Equal(t, []TestSearchSort{{Field: "id"}}, req.Params.Sorts)
+		return nil, nil
+	})
+	query := url.Values{
+		"q[range][from]":    {"1"},
+		"q[range][to]":      {"5"},
+		"q[tags][]":         {"a", "b"},
+		"q[sort][0][field]": {"name"},
+		"q[sort][1][field]": {"age"},
+		"q[sort][1][desc]":  {"true"},
+		"q[extra][x][]":     {"1", "2"},
+		"d.range.from":      {"1"},
+		"d.range.to":        {"5"},
+		"d.tags":            {"a", "b"},
+		"d.sort[0].field":   {"name"},
+		"d.sort[1].field":   {"age"},
+		"d.sort[1].desc":    {"true"},
+		"d.extra.x[0]":      {"1"},
+		"d.extra.x[1]":      {"2"},
+		"sorts[0][field]":   {"id"},
+		"q[range][unknown]": {"ignored"},
+		"qualifier":         {"ignored"},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/search?"+query.Encode(), nil)
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// invalid values are improperly formatted params
+	req = httptest.NewRequest(http.MethodGet, "/search?"+url.Values{"q[range][from]": {"one"}}.Encode(), nil)
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"q"`)
+
+	// requests are written the same way they are read
+	written := httptest.NewRequest(http.MethodGet, "/search", nil)
+	assert.NoError(t, chimera.MarshalRequestParams(written, &TestNestedParams{Q: expected, Dotted: expected}))
+	values := written.URL.Query()
+	assert.Equal(t, []string{"a", "b"}, values["q[tags][]"])
+	assert.Equal(t, []string{"a", "b"}, values["d.tags"])
+	assert.Equal(t, "age", values.Get("q[sort][1][field]"))
+	assert.Equal(t, "5", values.Get("d.range.to"))
+	assert.Equal(t, []string{"1", "2"}, values["d.extra.x"])
+
+	// the schema is flattened into patternProperties
+	for _, param := range route.OpenAPIOperationSpec().Parameters {
+		assert.Equal(t, "deepObject", param.Style)
+		switch param.Name {
+		case "q":
+			assert.Contains(t, param.Schema.PatternProperties, `^q\[range\]\[from\]$`)
+			assert.Contains(t, param.Schema.PatternProperties, `^q\[tags\](\[\d*\])?$`)
+			assert.Contains(t, param.Schema.PatternProperties, `^q\[sort\]\[\d+\]\[field\]$`)
+			assert.Contains(t, param.Schema.PatternProperties, `^q\[extra\]\[[^\[\]\.]+\](\[\d*\])?$`)
+		case "d":
+			assert.Contains(t, param.Schema.PatternProperties, `^d\.range\.from$`)
+			assert.Contains(t, param.Schema.PatternProperties, `^d\.sort\[\d+\]\.desc$`)
+		}
+	}
+
+	type invalidNested struct {
+		Q TestSearchQuery `param:"q,in=query,style=form,nested=brackets"`
+	}
+	assert.Panics(t, func() {
+		chimera.Get(api, "/invalid", func(req *chimera.NoBodyRequest[invalidNested]) (*chimera.EmptyResponse, error) {
+			return nil, nil
+		})
+	})
+}
+
+func TestNestedQueryParamIndexes(t *testing.T) {
+	api := chimera.NewAPI()
+	sorts := []TestSearchSort{}
+	chimera.Get(api, "/search", func(req *chimera.NoBodyRequest[TestNestedParams]) (*chimera.EmptyResponse, error) {
+		sorts = req.Params.Sorts
+		return nil, nil
+	})
+
+	// sparse indexes leave zero values
+	req := httptest.NewRequest(http.MethodGet, "/search?"+url.Values{"sorts[2][field]": {"id"}}.Encode(), nil)
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []TestSearchSort{{}, {}, {Field: "id"}}, sorts)
+
+	// huge indexes are invalid instead of allocating
+	for _, index := range []string{"1001", "50000000", "99999999999999", "99999999999999999999999", "-1"} {
+		req = httptest.NewRequest(http.MethodGet, "/search?"+url.Values{"sorts[" + index + "][field]": {"id"}}.Encode(), nil)
+		w = httptest.NewRecorder()
+		api.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, index)
+		assert.Contains(t, w.Body.String(), `"name":"sorts"`)
+	}
+}
+
+type TestSearchPage struct {
+	Limit int `prop:"limit"`
+}
+
+type TestEmbeddedSearch struct {
+	TestSearchPage
+	*TestSearchRange
+	Tags []string `prop:"tags"`
+}
+
+type TestEmbeddedNestedParams struct {
+	Q TestEmbeddedSearch `param:"q,in=query,nested=brackets"`
+}
+
+func TestNestedQueryParamEmbedded(t *testing.T) {
+	expected := TestEmbeddedSearch{
+		TestSearchPage:  TestSearchPage{Limit: 10},
+		TestSearchRange: &TestSearchRange{From: 1},
+		Tags:            []string{"a"},
+	}
+	api := chimera.NewAPI()
+	route := chimera.Get(api, "/search", func(req *chimera.NoBodyRequest[TestEmbeddedNestedParams]) (*chimera.EmptyResponse, error) {
+		assert.Equal(t, expected, req.Params.Q)
+		return nil, nil
+	})
+
+	// the props of embedded structs are flattened like in the schema
+	query := url.Values{"q[limit]": {"10"}, "q[from]": {"1"}, "q[tags][]": {"a"}}
+	req := httptest.NewRequest(http.MethodGet, "/search?"+query.Encode(), nil)
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	patterns := make([]string, 0)
+	for pattern := range route.OpenAPIOperationSpec().Parameters[0].Schema.PatternProperties {
+		patterns = append(patterns, pattern)
+	}
+	assert.ElementsMatch(t, []string{`^q\[limit\]$`, `^q\[from\]$`, `^q\[to\]$`, `^q\[tags\](\[\d*\])?$`}, patterns)
+
+	// and written the same way
+	req = httptest.NewRequest(http.MethodGet, "/search", nil)
+	assert.NoError(t, (&chimera.NoBodyRequest[TestEmbeddedNestedParams]{Params: TestEmbeddedNestedParams{Q: expected}}).WriteRequest(req))
+	assert.Equal(t, url.Values{"q[limit]": {"10"}, "q[from]": {"1"}, "q[to]": {"0"}, "q[tags][]": {"a"}}, req.URL.Query())
+}
@@ -2,6 +2,7 @@ package chimera
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 
 	"github.com/matt1484/spectagular"
@@ -16,8 +17,10 @@ type ResponseStructTag struct {
 
 var (
 	_                   ResponseWriter = new(OneOfResponse[Nil])
+	_                   ResponseReader = new(OneOfResponse[Nil])
 	responseTagCache, _                = spectagular.NewFieldTagCache[ResponseStructTag]("response")
 	responseWriterType                 = reflect.TypeOf((*ResponseWriter)(nil)).Elem()
+	responseReaderType                 = reflect.TypeOf((*ResponseReader)(nil)).Elem()
 )
 
 // OneOfResponse[ResponseType any] is a response that uses the fields of
@@ -88,6 +91,52 @@ func (r *OneOfResponse[ResponseType]) WriteHead(head *ResponseHead) error {
 	return nil
 }
 
+// responseFieldIndex finds the field of ResponseType that describes a status code. Exact matches
+// take precedence over fields with no status code (which match any 2XX status code)
+func (r *OneOfResponse[ResponseType]) responseFieldIndex(statusCode int) (int, bool) {
+	tags, err := responseTagCache.GetOrAdd(reflect.TypeOf(r.Response))
+	if err != nil {
+		return 0, false
+	}
+	index := -1
+	for _, tag := range tags {
+		if tag.Value.StatusCode == statusCode {
+			return tag.FieldIndex, true
+		}
+		if tag.Value.StatusCode == 0 && statusCode >= 200 && statusCode < 300 && index < 0 {
+			index = tag.FieldIndex
+		}
+	}
+	return index, index >= 0
+}
+
+// readsStatusCode returns true if ResponseType has a field for the status code
+func (r *OneOfResponse[ResponseType]) readsStatusCode(statusCode int) bool {
+	_, ok := r.responseFieldIndex(statusCode)
+	return ok
+}
+
+// ReadResponse reads the response into the field of ResponseType that matches the response's status code
+// all other fields are set to nil
+func (r *OneOfResponse[ResponseType]) ReadResponse(resp *http.Response) error {
+	index, ok := r.responseFieldIndex(resp.StatusCode)
+	if !ok {
+		return fmt.Errorf("chimera.OneOfResponse[Body]: no field of %s for status code %d", reflect.TypeOf(r.Response).Name(), resp.StatusCode)
+	}
+	body := reflect.ValueOf(&r.Response).Elem()
+	body.Set(reflect.Zero(body.Type()))
+	field := body.Field(index)
+	if field.Kind() != reflect.Pointer || !field.Type().Implements(responseReaderType) {
+		return fmt.Errorf("chimera.OneOfResponse[Body]: field %s does not implement chimera.ResponseReader", body.Type().Field(index).Name)
+	}
+	value := reflect.New(field.Type().Elem())
+	if err := value.Interface().(ResponseReader).ReadResponse(resp); err != nil {
+		return err
+	}
+	field.Set(value)
+	return nil
+}
+
 // NewOneOfResponse creates a OneOfResponse from a response
 func NewOneOfResponse[ResponseType any](response ResponseType) *OneOfResponse[ResponseType] {
 	return &OneOfResponse[ResponseType]{
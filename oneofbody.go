@@ -0,0 +1,239 @@
+package chimera
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/invopop/jsonschema"
+	"github.com/matt1484/spectagular"
+)
+
+// OneOfBodyStructTag represents the "oneOf" struct tag used by OneOfBody, it has the
+// discriminator value of the variant and the name of the discriminator property
+type OneOfBodyStructTag struct {
+	Value         string `structtag:"$name"`
+	Discriminator string `structtag:"discriminator"`
+}
+
+var (
+	oneOfBodyTagCache, _ = spectagular.NewFieldTagCache[OneOfBodyStructTag]("oneOf")
+	oneOfBodyType        = reflect.TypeOf((*oneOfBody)(nil)).Elem()
+	// oneOfUnions caches the variants of every OneOfBody union (see oneOfVariants)
+	oneOfUnions = sync.Map{}
+)
+
+// oneOfBody is implemented by every OneOfBody so that its schema can be reflected (see oneOfBodySchema)
+type oneOfBody interface {
+	unionType() reflect.Type
+}
+
+// OneOfBody[Union any] is a JSON body that is one of the (pointer) fields of Union, the field is chosen
+// using a discriminator property whose name and values are set by the "oneOf" struct tag i.e.
+//
+//	type Payment struct {
+//		Card *CardPayment `oneOf:"card,discriminator=kind"`
+//		Bank *BankPayment `oneOf:"bank"`
+//	}
+//
+// reads {"kind": "card", ...} into Card. Only one field should be non-nil when writing (the first one is used).
+// It can be used as the Body of JSON requests and responses (i.e. JSON[OneOfBody[Payment], Nil]).
+type OneOfBody[Union any] struct {
+	Value Union
+}
+
+// oneOfVariant is a field of a OneOfBody union
+type oneOfVariant struct {
+	value      string
+	fieldIndex int
+	fieldType  reflect.Type
+}
+
+// oneOfUnion is the discriminator property and variants of a OneOfBody union (or the reason it is invalid)
+type oneOfUnion struct {
+	discriminator string
+	variants      []oneOfVariant
+	err           error
+}
+
+// oneOfVariants gets the (cached) discriminator property and the variants of a OneOfBody union (sorted by value),
+// invalid unions return an error
+func oneOfVariants(t reflect.Type) (string, []oneOfVariant, error) {
+	if union, ok := oneOfUnions.Load(t); ok {
+		u := union.(oneOfUnion)
+		return u.discriminator, u.variants, u.err
+	}
+	discriminator, variants, err := parseOneOfVariants(t)
+	oneOfUnions.Store(t, oneOfUnion{discriminator: discriminator, variants: variants, err: err})
+	return discriminator, variants, err
+}
+
+// parseOneOfVariants parses the oneOf tags of a OneOfBody union
+func parseOneOfVariants(t reflect.Type) (string, []oneOfVariant, error) {
+	if t.Kind() != reflect.Struct {
+		return "", nil, errors.New("chimera.OneOfBody[Union]: Union must be a struct")
+	}
+	tags, err := oneOfBodyTagCache.GetOrAdd(t)
+	if err != nil {
+		return "", nil, err
+	}
+	discriminator := ""
+	variants := make([]oneOfVariant, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if tag.Value.Value == spectagular.EmptyTag || tag.Value.Value == spectagular.SkipTag {
+			continue
+		}
+		field := t.Field(tag.FieldIndex)
+		if field.Type.Kind() != reflect.Pointer {
+			return "", nil, errors.New("chimera.OneOfBody[Union]: field " + field.Name + " must be a pointer")
+		}
+		if tag.Value.Discriminator != "" {
+			if discriminator != "" && discriminator != tag.Value.Discriminator {
+				return "", nil, errors.New("chimera.OneOfBody[Union]: fields of " + t.Name() + " use different discriminators")
+			}
+			discriminator = tag.Value.Discriminator
+		}
+		if seen[tag.Value.Value] {
+			return "", nil, errors.New("chimera.OneOfBody[Union]: duplicate discriminator value " + tag.Value.Value)
+		}
+		seen[tag.Value.Value] = true
+		variants = append(variants, oneOfVariant{
+			value:      tag.Value.Value,
+			fieldIndex: tag.FieldIndex,
+			fieldType:  field.Type.Elem(),
+		})
+	}
+	if discriminator == "" || len(variants) == 0 {
+		return "", nil, errors.New("chimera.OneOfBody[Union]: " + t.Name() + " must have oneOf fields with a discriminator")
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].value < variants[j].value })
+	return discriminator, variants, nil
+}
+
+// unionType returns the type of Union
+func (OneOfBody[Union]) unionType() reflect.Type {
+	return reflect.TypeOf(*new(Union))
+}
+
+// MarshalJSON writes the first non-nil field of the union with its discriminator property set to the field's value
+func (b OneOfBody[Union]) MarshalJSON() ([]byte, error) {
+	union := reflect.ValueOf(b.Value)
+	discriminator, variants, err := oneOfVariants(union.Type())
+	if err != nil {
+		return nil, err
+	}
+	for _, variant := range variants {
+		field := union.Field(variant.fieldIndex)
+		if field.IsNil() {
+			continue
+		}
+		data, err := json.Marshal(field.Interface())
+		if err != nil {
+			return nil, err
+		}
+		props := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(data, &props); err != nil {
+			// not an object so there is nowhere to put the discriminator
+			return data, nil
+		}
+		// the field decides the variant so the discriminator always matches it
+		props[discriminator], _ = json.Marshal(variant.value)
+		return json.Marshal(props)
+	}
+	return []byte("null"), nil
+}
+
+// UnmarshalJSON reads the discriminator property and unmarshals the body into the matching field,
+// unknown (or non-string) discriminator values are rejected with a 422
+func (b *OneOfBody[Union]) UnmarshalJSON(data []byte) error {
+	union := reflect.ValueOf(&b.Value).Elem()
+	union.Set(reflect.Zero(union.Type()))
+	discriminator, variants, err := oneOfVariants(union.Type())
+	if err != nil {
+		return err
+	}
+	props := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &props); err != nil {
+		return err
+	}
+	var value string
+	if raw, ok := props[discriminator]; ok {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return ParamsError{{
+				In:     "body",
+				Name:   discriminator,
+				Value:  string(raw),
+				Reason: "must be a string",
+			}}
+		}
+	}
+	for _, variant := range variants {
+		if variant.value != value {
+			continue
+		}
+		field := reflect.New(variant.fieldType)
+		if err := json.Unmarshal(data, field.Interface()); err != nil {
+			return err
+		}
+		union.Field(variant.fieldIndex).Set(field)
+		return nil
+	}
+	values := make([]string, len(variants))
+	for i, variant := range variants {
+		values[i] = variant.value
+	}
+	return ParamsError{{
+		In:     "body",
+		Name:   discriminator,
+		Value:  value,
+		Reason: "must be one of " + strings.Join(values, ", "),
+	}}
+}
+
+// oneOfBodySchema returns the schema of a OneOfBody type (oneOf the schemas of its fields with a discriminator)
+// using reflector, nil is returned for other types. It is called when a route is added (i.e. while its body is reflected)
+// so invalid unions panic at registration
+func oneOfBodySchema(reflector jsonschema.Reflector, t reflect.Type) *jsonschema.Schema {
+	if !t.Implements(oneOfBodyType) {
+		return nil
+	}
+	unionType := reflect.Zero(t).Interface().(oneOfBody).unionType()
+	discriminator, variants, err := oneOfVariants(unionType)
+	if err != nil {
+		panic(err.Error())
+	}
+	schema := &jsonschema.Schema{
+		OneOf: make([]*jsonschema.Schema, len(variants)),
+	}
+	mapping := make(map[string]string)
+	for i, variant := range variants {
+		s := newReflector(reflector).Reflect(reflect.New(variant.fieldType).Interface())
+		s.Version = ""
+		s.ID = ""
+		name := SchemaName(variant.fieldType)
+		def := s
+		if d, ok := s.Definitions[name]; ok {
+			def = d
+			mapping[variant.value] = "#/components/schemas/" + name
+		}
+		// OpenAPI requires every variant to have the discriminator property
+		if def.Properties == nil {
+			panic("chimera.OneOfBody[Union]: " + variant.fieldType.String() + " must be an object")
+		}
+		if _, ok := def.Properties.Get(discriminator); !ok {
+			panic("chimera.OneOfBody[Union]: " + variant.fieldType.String() + " is missing the discriminator property " + discriminator)
+		}
+		schema.OneOf[i] = s
+	}
+	schema.Extras = map[string]any{
+		"discriminator": map[string]any{
+			"propertyName": discriminator,
+			"mapping":      mapping,
+		},
+	}
+	return schema
+}
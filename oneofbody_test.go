@@ -0,0 +1,101 @@
+package chimera_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestCardPayment struct {
+	Kind   string `json:"kind"`
+	Number string `json:"number"`
+}
+
+type TestBankPayment struct {
+	Kind    string `json:"kind"`
+	Account string `json:"account"`
+}
+
+type TestPayment struct {
+	Card *TestCardPayment `oneOf:"card,discriminator=kind"`
+	Bank *TestBankPayment `oneOf:"bank"`
+}
+
+func TestOneOfBody(t *testing.T) {
+	api := chimera.NewAPI()
+	route := chimera.Post(api, "/payments", func(req *chimera.JSON[chimera.OneOfBody[TestPayment], chimera.Nil]) (*chimera.JSON[chimera.OneOfBody[TestPayment], chimera.Nil], error) {
+		if req.Body.Value.Card != nil {
+			assert.Nil(t, req.Body.Value.Bank)
+			assert.Equal(t, "4242", req.Body.Value.Card.Number)
+			// the discriminator is added when it is missing
+			return &chimera.JSON[chimera.OneOfBody[TestPayment], chimera.Nil]{
+				Body: chimera.OneOfBody[TestPayment]{Value: TestPayment{Bank: &TestBankPayment{Account: "123"}}},
+			}, nil
+		}
+		return &chimera.JSON[chimera.OneOfBody[TestPayment], chimera.Nil]{Body: req.Body}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"kind": "card", "number": "4242"}`))
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"kind": "bank", "account": "123"}`, w.Body.String())
+
+	// responses can be read back into the matching field
+	resp := chimera.JSON[chimera.OneOfBody[TestPayment], chimera.Nil]{}
+	assert.NoError(t, resp.ReadResponse(w.Result()))
+	assert.Equal(t, &TestBankPayment{Kind: "bank", Account: "123"}, resp.Body.Value.Bank)
+	assert.Nil(t, resp.Body.Value.Card)
+
+	// unknown variants are rejected
+	req = httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"kind": "cash"}`))
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"errors": [{"in": "body", "name": "kind", "value": "cash", "reason": "must be one of bank, card"}]}`, w.Body.String())
+
+	// non-string discriminators are rejected too
+	req = httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"kind": 5}`))
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"errors": [{"in": "body", "name": "kind", "value": "5", "reason": "must be a string"}]}`, w.Body.String())
+
+	// the discriminator always matches the field that is set
+	b, err := json.Marshal(chimera.OneOfBody[TestPayment]{Value: TestPayment{Card: &TestCardPayment{Kind: "bank", Number: "4242"}}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kind": "card", "number": "4242"}`, string(b))
+
+	// the schema is oneOf the variants with a discriminator
+	union, err := json.Marshal(route.OpenAPIOperationSpec().RequestBody.Content["application/json"].Schema)
+	assert.NoError(t, err)
+	components := api.OpenAPISpec().Components.Schemas
+	assert.JSONEq(t, `{
+		"oneOf": [
+			{"$ref": "#/components/schemas/TestBankPayment"},
+			{"$ref": "#/components/schemas/TestCardPayment"}
+		],
+		"discriminator": {
+			"propertyName": "kind",
+			"mapping": {
+				"bank": "#/components/schemas/TestBankPayment",
+				"card": "#/components/schemas/TestCardPayment"
+			}
+		}
+	}`, string(union))
+	assert.Contains(t, components, "TestCardPayment")
+
+	type invalidUnion struct {
+		Card *TestCardPayment `oneOf:"card"`
+	}
+	assert.Panics(t, func() {
+		chimera.Post(api, "/invalid", func(req *chimera.JSON[chimera.OneOfBody[invalidUnion], chimera.Nil]) (*chimera.EmptyResponse, error) {
+			return nil, nil
+		})
+	})
+}
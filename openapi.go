@@ -1,6 +1,10 @@
 package chimera
 
 import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
 	"github.com/invopop/jsonschema"
 )
 
@@ -50,8 +54,11 @@ func (o *OpenAPI) Merge(spec OpenAPI) {
 			if pathObj.Trace == nil {
 				pathObj.Trace = obj.Trace
 			}
-			// TODO: handle dupes?
-			pathObj.Parameters = append(pathObj.Parameters, obj.Parameters...)
+			for _, param := range obj.Parameters {
+				if !hasParameter(pathObj.Parameters, param) {
+					pathObj.Parameters = append(pathObj.Parameters, param)
+				}
+			}
 			if pathObj.Description == "" {
 				pathObj.Description = obj.Description
 			} else {
@@ -189,6 +196,61 @@ type Path struct {
 	Parameters  []Parameter `json:"parameters,omitempty"`
 }
 
+// hasParameter checks if a parameter with the same name and location is in params
+func hasParameter(params []Parameter, param Parameter) bool {
+	for _, p := range params {
+		if p.Name == param.Name && p.In == param.In {
+			return true
+		}
+	}
+	return false
+}
+
+// Operation returns the operation for an http method (or nil if there isnt one)
+func (p *Path) Operation(method string) *Operation {
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		return p.Get
+	case http.MethodPut:
+		return p.Put
+	case http.MethodPost:
+		return p.Post
+	case http.MethodDelete:
+		return p.Delete
+	case http.MethodOptions:
+		return p.Options
+	case http.MethodHead:
+		return p.Head
+	case http.MethodPatch:
+		return p.Patch
+	case http.MethodTrace:
+		return p.Trace
+	}
+	return nil
+}
+
+// SetOperation sets the operation for an http method
+func (p *Path) SetOperation(method string, op *Operation) {
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		p.Get = op
+	case http.MethodPut:
+		p.Put = op
+	case http.MethodPost:
+		p.Post = op
+	case http.MethodDelete:
+		p.Delete = op
+	case http.MethodOptions:
+		p.Options = op
+	case http.MethodHead:
+		p.Head = op
+	case http.MethodPatch:
+		p.Patch = op
+	case http.MethodTrace:
+		p.Trace = op
+	}
+}
+
 // RequestSpec is the description of an openapi request used in an Operation
 type RequestSpec struct {
 	Parameters  []Parameter  `json:"parameters,omitempty"`
@@ -271,6 +333,9 @@ func (o *Operation) Merge(other Operation) {
 	}
 	o.Servers = append(o.Servers, other.Servers...)
 	o.Responses.Merge(other.Responses)
+	if len(other.Callbacks) > 0 && o.Callbacks == nil {
+		o.Callbacks = make(map[string]map[string]Path)
+	}
 	for k, cb := range other.Callbacks {
 		if v, ok := o.Callbacks[k]; ok {
 			for p, path := range cb {
@@ -374,6 +439,21 @@ type Components struct {
 	PathItems       map[string]Path                 `json:"pathItems,omitempty"`
 }
 
+// MarshalJSON marshals the components using pointers to the schemas since jsonschema.Schema
+// only implements json.Marshaler for pointers (otherwise extras like discriminators are lost)
+func (c Components) MarshalJSON() ([]byte, error) {
+	type components Components
+	schemas := make(map[string]*jsonschema.Schema, len(c.Schemas))
+	for name := range c.Schemas {
+		schema := c.Schemas[name]
+		schemas[name] = &schema
+	}
+	return json.Marshal(struct {
+		components
+		Schemas map[string]*jsonschema.Schema `json:"schemas,omitempty"`
+	}{components(c), schemas})
+}
+
 // Tag is used to tag parts of an API
 type Tag struct {
 	Name        string `json:"name"`
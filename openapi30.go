@@ -0,0 +1,358 @@
+package chimera
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	openAPI30Version = "3.0.3"
+)
+
+// ConversionError describes part of a spec that could not be converted to OpenAPI 3.0
+type ConversionError struct {
+	// Pointer is the JSON pointer of the part of the (3.1) spec that could not be converted
+	Pointer string
+	Reason  string
+}
+
+// ConversionErrors is a collection of every ConversionError found while converting a spec
+type ConversionErrors []ConversionError
+
+// Error returns the string representation of the errors
+func (c ConversionErrors) Error() string {
+	messages := make([]string, len(c))
+	for i, err := range c {
+		messages[i] = err.Pointer + ": " + err.Reason
+	}
+	return "could not convert to OpenAPI " + openAPI30Version + ": " + strings.Join(messages, ", ")
+}
+
+// converter30 holds the state of a conversion from OpenAPI 3.1 to 3.0
+type converter30 struct {
+	errs    ConversionErrors
+	hoisted map[string]any
+	// hoistedFrom is the pointer of every hoisted schema
+	hoistedFrom map[string]string
+}
+
+// As30 converts the spec to an OpenAPI 3.0 document for tools that dont support 3.1 yet. Schemas are rewritten to
+// their 3.0 equivalents (i.e. type arrays with "null" become nullable, const becomes a single value enum, numeric
+// exclusiveMinimum becomes minimum with exclusiveMinimum: true). Anything that can't be converted is dropped and
+// reported in a ConversionErrors error, the rest of the document is still returned.
+func (o *OpenAPI) As30() (map[string]any, error) {
+	raw, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]any)
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	c := converter30{hoisted: make(map[string]any), hoistedFrom: make(map[string]string)}
+	c.convertDoc(doc)
+	if len(c.errs) > 0 {
+		sort.SliceStable(c.errs, func(i, j int) bool { return c.errs[i].Pointer < c.errs[j].Pointer })
+		return doc, c.errs
+	}
+	return doc, nil
+}
+
+// report adds a ConversionError
+func (c *converter30) report(pointer, reason string) {
+	c.errs = append(c.errs, ConversionError{Pointer: pointer, Reason: reason})
+}
+
+// pointerJoin adds a token to a JSON pointer
+func pointerJoin(pointer, token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+	return pointer + "/" + token
+}
+
+// convertDoc converts the top level fields of a document and then walks it looking for schemas
+func (c *converter30) convertDoc(doc map[string]any) {
+	doc["openapi"] = openAPI30Version
+	delete(doc, "jsonSchemaDialect")
+	if info, ok := doc["info"].(map[string]any); ok {
+		if _, ok := info["summary"]; ok {
+			c.report("/info/summary", "info.summary is not supported")
+			delete(info, "summary")
+		}
+		if license, ok := info["license"].(map[string]any); ok {
+			if _, ok := license["identifier"]; ok {
+				c.report("/info/license/identifier", "license.identifier is not supported")
+				delete(license, "identifier")
+			}
+		}
+	}
+	if _, ok := doc["webhooks"]; ok {
+		c.report("/webhooks", "webhooks are not supported")
+		delete(doc, "webhooks")
+	}
+	if _, ok := doc["paths"]; !ok {
+		doc["paths"] = map[string]any{}
+	}
+	components, _ := doc["components"].(map[string]any)
+	if components != nil {
+		if _, ok := components["pathItems"]; ok {
+			c.report("/components/pathItems", "components.pathItems are not supported")
+			delete(components, "pathItems")
+		}
+		if schemas, ok := components["schemas"].(map[string]any); ok {
+			for name, schema := range schemas {
+				schemas[name] = c.convertSchema(schema, pointerJoin("/components/schemas", name))
+			}
+		}
+	}
+	for key, value := range doc {
+		if key != "components" {
+			c.walk(value, pointerJoin("", key))
+		}
+	}
+	if components != nil {
+		for key, value := range components {
+			if key != "schemas" {
+				c.walk(value, pointerJoin("/components", key))
+			}
+		}
+	}
+	if len(c.hoisted) > 0 {
+		if components == nil {
+			components = make(map[string]any)
+			doc["components"] = components
+		}
+		schemas, _ := components["schemas"].(map[string]any)
+		if schemas == nil {
+			schemas = make(map[string]any)
+			components["schemas"] = schemas
+		}
+		for name, schema := range c.hoisted {
+			existing, ok := schemas[name]
+			if !ok {
+				schemas[name] = schema
+			} else if !reflect.DeepEqual(existing, schema) {
+				c.report(c.hoistedFrom[name], "conflicts with the component schema "+name+" (refs to it point at the component)")
+			}
+		}
+	}
+}
+
+// walk looks for schemas in the non-schema parts of a document
+func (c *converter30) walk(node any, pointer string) {
+	switch node := node.(type) {
+	case map[string]any:
+		for key, value := range node {
+			switch {
+			case key == "schema":
+				node[key] = c.convertSchema(value, pointerJoin(pointer, key))
+			case key == "example" || key == "examples" || strings.HasPrefix(key, "x-"):
+				// examples and extensions are values, not specs
+			default:
+				c.walk(value, pointerJoin(pointer, key))
+			}
+		}
+	case []any:
+		for i, value := range node {
+			c.walk(value, pointerJoin(pointer, strconv.Itoa(i)))
+		}
+	}
+}
+
+// convertSchema converts a JSON schema (2020-12) into an OpenAPI 3.0 schema object
+func (c *converter30) convertSchema(node any, pointer string) any {
+	switch node := node.(type) {
+	case bool:
+		if node {
+			return map[string]any{}
+		}
+		return map[string]any{"not": map[string]any{}}
+	case map[string]any:
+		c.convertSchemaMap(node, pointer)
+		return node
+	}
+	return node
+}
+
+// convertSchemaMap converts a schema object in place
+func (c *converter30) convertSchemaMap(schema map[string]any, pointer string) {
+	// null branches become nullable (before they are converted themselves)
+	for _, key := range []string{"anyOf", "oneOf"} {
+		subs, ok := schema[key].([]any)
+		if !ok {
+			continue
+		}
+		filtered := make([]any, 0, len(subs))
+		for _, sub := range subs {
+			if sub, ok := sub.(map[string]any); ok && len(sub) == 1 && sub["type"] == "null" {
+				schema["nullable"] = true
+				continue
+			}
+			filtered = append(filtered, sub)
+		}
+		schema[key] = filtered
+	}
+
+	// sub-schemas
+	for _, key := range []string{"$defs", "definitions"} {
+		if defs, ok := schema[key].(map[string]any); ok {
+			for name, def := range defs {
+				defPointer := pointerJoin(pointerJoin(pointer, key), name)
+				c.hoist(name, c.convertSchema(def, defPointer), defPointer)
+			}
+			delete(schema, key)
+		}
+	}
+	if props, ok := schema["properties"].(map[string]any); ok {
+		for name, prop := range props {
+			props[name] = c.convertSchema(prop, pointerJoin(pointerJoin(pointer, "properties"), name))
+		}
+	}
+	for _, key := range []string{"items", "not"} {
+		if sub, ok := schema[key]; ok {
+			schema[key] = c.convertSchema(sub, pointerJoin(pointer, key))
+		}
+	}
+	if sub, ok := schema["additionalProperties"]; ok {
+		if _, isBool := sub.(bool); !isBool {
+			schema["additionalProperties"] = c.convertSchema(sub, pointerJoin(pointer, "additionalProperties"))
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if subs, ok := schema[key].([]any); ok {
+			for i, sub := range subs {
+				subs[i] = c.convertSchema(sub, pointerJoin(pointerJoin(pointer, key), strconv.Itoa(i)))
+			}
+		}
+	}
+
+	// keywords that only exist in newer drafts
+	for _, key := range []string{"$schema", "$id", "$comment", "$anchor", "contentMediaType"} {
+		delete(schema, key)
+	}
+	for _, key := range []string{
+		"prefixItems", "patternProperties", "propertyNames", "contains", "minContains", "maxContains",
+		"if", "then", "else", "dependentSchemas", "dependentRequired", "unevaluatedItems",
+		"unevaluatedProperties", "contentSchema", "$dynamicRef", "$dynamicAnchor",
+	} {
+		if _, ok := schema[key]; ok {
+			c.report(pointerJoin(pointer, key), key+" is not supported")
+			delete(schema, key)
+		}
+	}
+	if encoding, ok := schema["contentEncoding"]; ok {
+		if encoding == "base64" {
+			schema["format"] = "byte"
+		} else {
+			c.report(pointerJoin(pointer, "contentEncoding"), "only base64 contentEncoding is supported")
+		}
+		delete(schema, "contentEncoding")
+	}
+	if value, ok := schema["const"]; ok {
+		schema["enum"] = []any{value}
+		delete(schema, "const")
+	}
+	if examples, ok := schema["examples"].([]any); ok {
+		if len(examples) > 0 {
+			schema["example"] = examples[0]
+		}
+		delete(schema, "examples")
+	}
+	for _, key := range []string{"Minimum", "Maximum"} {
+		exclusive := "exclusive" + key
+		bound := strings.ToLower(key)
+		value, ok := schema[exclusive].(float64)
+		if !ok {
+			continue
+		}
+		// 3.0 only has one bound so the stricter one is kept
+		inclusive, hasInclusive := schema[bound].(float64)
+		stricter := !hasInclusive || (key == "Minimum" && value >= inclusive) || (key == "Maximum" && value <= inclusive)
+		if stricter {
+			schema[bound] = value
+			schema[exclusive] = true
+		} else {
+			delete(schema, exclusive)
+		}
+	}
+
+	// types
+	switch types := schema["type"].(type) {
+	case []any:
+		nonNull := make([]any, 0, len(types))
+		for _, t := range types {
+			if t == "null" {
+				schema["nullable"] = true
+			} else {
+				nonNull = append(nonNull, t)
+			}
+		}
+		switch len(nonNull) {
+		case 0:
+			delete(schema, "type")
+		case 1:
+			schema["type"] = nonNull[0]
+		default:
+			delete(schema, "type")
+			anyOf := make([]any, len(nonNull))
+			for i, t := range nonNull {
+				anyOf[i] = map[string]any{"type": t}
+			}
+			if _, ok := schema["anyOf"]; ok {
+				schema["allOf"] = append(toSlice(schema["allOf"]), map[string]any{"anyOf": anyOf})
+			} else {
+				schema["anyOf"] = anyOf
+			}
+		}
+	case string:
+		if types == "null" {
+			c.report(pointerJoin(pointer, "type"), "type null is not supported (it is converted to nullable)")
+			delete(schema, "type")
+			schema["nullable"] = true
+		}
+	}
+	// refs
+	if ref, ok := schema["$ref"].(string); ok {
+		for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+			if strings.HasPrefix(ref, prefix) {
+				ref = "#/components/schemas/" + strings.TrimPrefix(ref, prefix)
+			}
+		}
+		if len(schema) > 1 {
+			// siblings of $ref are ignored in 3.0
+			delete(schema, "$ref")
+			schema["allOf"] = append([]any{map[string]any{"$ref": ref}}, toSlice(schema["allOf"])...)
+		} else {
+			schema["$ref"] = ref
+		}
+	}
+}
+
+// hoist adds a converted $defs/definitions entry to the schemas that are moved to the components,
+// entries with the same name but different content are reported and the one with the first pointer is kept
+// (refs can only point at one of them)
+func (c *converter30) hoist(name string, schema any, pointer string) {
+	if existing, ok := c.hoisted[name]; ok {
+		first, other := c.hoistedFrom[name], pointer
+		if other < first {
+			first, other = other, first
+		}
+		if !reflect.DeepEqual(existing, schema) {
+			c.report(other, "conflicts with the definition of "+name+" at "+first+" (refs to it point at that one)")
+		}
+		if first != pointer {
+			return
+		}
+	}
+	c.hoisted[name] = schema
+	c.hoistedFrom[name] = pointer
+}
+
+// toSlice returns value as a slice (or nil if it isnt one)
+func toSlice(value any) []any {
+	slice, _ := value.([]any)
+	return slice
+}
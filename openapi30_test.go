@@ -0,0 +1,115 @@
+package chimera_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestOpenAPI30Body struct {
+	Name  *string `json:"name"`
+	Count int     `json:"count" jsonschema:"exclusiveMinimum=0"`
+}
+
+func TestAs30(t *testing.T) {
+	api := chimera.NewAPI(chimera.WithDocs(chimera.DocsOptions{Spec30Path: "/openapi-3.0.json"}))
+	chimera.Post(api, "/items", func(*chimera.JSON[TestOpenAPI30Body, chimera.Nil]) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+	spec := api.OpenAPISpec()
+	spec.Info.Summary = "summary"
+	spec.Components.Schemas["Legacy"] = jsonschema.Schema{
+		Type:        "object",
+		Const:       "x",
+		Examples:    []any{"x", "y"},
+		PrefixItems: []*jsonschema.Schema{{Type: "string"}},
+		AnyOf:       []*jsonschema.Schema{{Ref: "#/$defs/Other"}, {Type: "null"}},
+		Definitions: jsonschema.Definitions{"Other": {Type: "string", ContentEncoding: "base64"}},
+		Extras:      map[string]any{"type": []any{"integer", "null"}},
+	}
+	// 3.0 has one bound per side so the stricter of minimum/exclusiveMinimum (and maximum/exclusiveMaximum) is kept
+	spec.Components.Schemas["Bounds"] = jsonschema.Schema{
+		Type:             "integer",
+		Minimum:          "5",
+		ExclusiveMinimum: "0",
+		Maximum:          "20",
+		ExclusiveMaximum: "10",
+	}
+	spec.Components.Schemas["ExclusiveBounds"] = jsonschema.Schema{
+		Type:             "integer",
+		Minimum:          "0",
+		ExclusiveMinimum: "3",
+		Maximum:          "10",
+		ExclusiveMaximum: "15",
+	}
+
+	doc, err := spec.As30()
+	assert.Equal(t, chimera.ConversionErrors{
+		{Pointer: "/components/schemas/Legacy/prefixItems", Reason: "prefixItems is not supported"},
+		{Pointer: "/info/summary", Reason: "info.summary is not supported"},
+	}, err)
+	assert.Equal(t, "3.0.3", doc["openapi"])
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "format": "byte"}, schemas["Other"])
+	legacy := schemas["Legacy"].(map[string]any)
+	assert.Equal(t, []any{"x"}, legacy["enum"])
+	assert.Equal(t, "x", legacy["example"])
+	assert.Equal(t, true, legacy["nullable"])
+	assert.Equal(t, []any{map[string]any{"$ref": "#/components/schemas/Other"}}, legacy["anyOf"])
+	assert.NotContains(t, legacy, "$defs")
+	assert.NotContains(t, legacy, "examples")
+	assert.NotContains(t, legacy, "const")
+	assert.Equal(t, map[string]any{"type": "integer", "minimum": float64(5), "maximum": float64(10), "exclusiveMaximum": true}, schemas["Bounds"])
+	assert.Equal(t, map[string]any{"type": "integer", "minimum": float64(3), "exclusiveMinimum": true, "maximum": float64(10)}, schemas["ExclusiveBounds"])
+
+	body := doc["paths"].(map[string]any)["/items"].(map[string]any)["post"].(map[string]any)["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	if ref, ok := body["$ref"].(string); ok {
+		assert.NotContains(t, ref, "$defs")
+	}
+	raw, _ := json.Marshal(doc)
+	assert.NotContains(t, string(raw), `"$defs"`)
+	assert.NotContains(t, string(raw), `"exclusiveMinimum":0`)
+	assert.Contains(t, string(raw), `"exclusiveMinimum":true`)
+
+	code, served := getDocs(t, api, "/openapi-3.0.json")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, served, `"openapi":"3.0.3"`)
+}
+
+func TestAs30DefinitionConflicts(t *testing.T) {
+	spec := chimera.OpenAPI{
+		Paths: map[string]chimera.Path{},
+		Components: &chimera.Components{
+			Schemas: map[string]jsonschema.Schema{
+				"A": {
+					Ref:         "#/$defs/Item",
+					Definitions: jsonschema.Definitions{"Item": {Type: "string"}, "Shared": {Type: "boolean"}},
+				},
+				"B": {
+					Ref:         "#/$defs/Item",
+					Definitions: jsonschema.Definitions{"Item": {Type: "integer"}, "Shared": {Type: "boolean"}},
+				},
+				"C": {
+					Ref:         "#/$defs/Existing",
+					Definitions: jsonschema.Definitions{"Existing": {Type: "number"}},
+				},
+				"Existing": {Type: "string"},
+			},
+		},
+	}
+
+	// definitions with the same name and different content cant all be hoisted
+	doc, err := spec.As30()
+	assert.Equal(t, chimera.ConversionErrors{
+		{Pointer: "/components/schemas/B/$defs/Item", Reason: "conflicts with the definition of Item at /components/schemas/A/$defs/Item (refs to it point at that one)"},
+		{Pointer: "/components/schemas/C/$defs/Existing", Reason: "conflicts with the component schema Existing (refs to it point at the component)"},
+	}, err)
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string"}, schemas["Item"])
+	assert.Equal(t, map[string]any{"type": "boolean"}, schemas["Shared"])
+	assert.Equal(t, map[string]any{"type": "string"}, schemas["Existing"])
+}
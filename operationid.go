@@ -0,0 +1,137 @@
+package chimera
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	anonymousFuncName = regexp.MustCompile(`^func\d+$`)
+)
+
+// OperationIDFunc generates the default operationId of a route from its method, full path (i.e. /group/route/{param})
+// and handler function
+type OperationIDFunc func(method, path string, handler any) string
+
+// WithOperationIDs sets how the default operationIds of routes are generated (defaults to OperationIDFromPath).
+// Groups and mounted APIs without their own OperationIDFunc use their parent's.
+func WithOperationIDs(f OperationIDFunc) APIOption {
+	return func(a *API) {
+		a.operationIDFunc = f
+	}
+}
+
+// OperationIDFromPath generates an operationId from the method and path (i.e. GET /users/{id}/posts becomes getUsersByIdPosts)
+func OperationIDFromPath(method, path string, handler any) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			id += "By"
+			segment = strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			first, size := utf8.DecodeRuneInString(word)
+			id += string(unicode.ToUpper(first)) + word[size:]
+		}
+	}
+	return id
+}
+
+// OperationIDFromHandler generates an operationId from the name of the handler function (i.e. ListUsers or
+// (*Server).ListUsers both become ListUsers), anonymous functions fall back to OperationIDFromPath
+func OperationIDFromHandler(method, path string, handler any) string {
+	value := reflect.ValueOf(handler)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return OperationIDFromPath(method, path, handler)
+	}
+	f := runtime.FuncForPC(value.Pointer())
+	if f == nil {
+		return OperationIDFromPath(method, path, handler)
+	}
+	name := f.Name()
+	name, _, _ = strings.Cut(name[strings.LastIndex(name, "/")+1:], "[")
+	name = strings.TrimSuffix(name, "-fm")
+	name = name[strings.LastIndex(name, ".")+1:]
+	if name == "" || anonymousFuncName.MatchString(name) {
+		return OperationIDFromPath(method, path, handler)
+	}
+	return name
+}
+
+// fullPath gets the path of a route including the base paths of its API and all of its parents
+func (a *API) fullPath(path string) string {
+	for api := a; api != nil; api = api.parent {
+		if api.parent != nil && api.basePath != "" {
+			path = "/" + strings.Trim(api.basePath, "/") + path
+		}
+	}
+	return path
+}
+
+// operationID generates the default operationId of a route using the nearest OperationIDFunc
+func (a *API) operationID(method, path string, handler any) string {
+	f := OperationIDFunc(OperationIDFromPath)
+	for api := a; api != nil; api = api.parent {
+		if api.operationIDFunc != nil {
+			f = api.operationIDFunc
+			break
+		}
+	}
+	return f(method, a.fullPath(path), handler)
+}
+
+// refreshOperationIDs regenerates the default operationIds of an API and its sub-APIs (i.e. after being mounted),
+// operationIds that were set explicitly are left alone
+func (a *API) refreshOperationIDs() {
+	for _, r := range a.routes {
+		if r.operationSpec == nil || r.operationSpec.OperationID != r.defaultOperationID {
+			continue
+		}
+		r.defaultOperationID = a.operationID(r.context.method, r.context.path, r.handlerFunc)
+		r.operationSpec.OperationID = r.defaultOperationID
+	}
+	for _, sub := range a.subAPIs {
+		sub.refreshOperationIDs()
+	}
+}
+
+// findDuplicateOperationID returns an error if 2 visible routes in the same API tree share an operationId
+// (routes that overwrite each other, i.e. same method and path, are allowed to)
+func findDuplicateOperationID(api *API) error {
+	root := api
+	for ; root.parent != nil; root = root.parent {
+	}
+	type seenRoute struct {
+		method string
+		path   string
+	}
+	seen := make(map[string]seenRoute)
+	var check func(a *API) error
+	check = func(a *API) error {
+		for _, r := range a.routes {
+			if r.hidden || r.operationSpec == nil || r.operationSpec.OperationID == "" {
+				continue
+			}
+			id := r.operationSpec.OperationID
+			current := seenRoute{method: r.context.method, path: a.fullPath(r.context.path)}
+			if other, ok := seen[id]; ok && other != current {
+				return fmt.Errorf("chimera: duplicate operationId %q used by %s %s and %s %s", id, other.method, other.path, current.method, current.path)
+			}
+			seen[id] = current
+		}
+		for _, sub := range a.subAPIs {
+			if err := check(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check(root)
+}
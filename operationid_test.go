@@ -0,0 +1,71 @@
+package chimera_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+func ListTestOperations(*chimera.EmptyRequest) (*chimera.EmptyResponse, error) {
+	return nil, nil
+}
+
+type testOperationServer struct{}
+
+func (*testOperationServer) GetTestOperation(*chimera.EmptyRequest) (*chimera.EmptyResponse, error) {
+	return nil, nil
+}
+
+func TestOperationIDs(t *testing.T) {
+	assert.Equal(t, "getUsersByIdPosts", chimera.OperationIDFromPath(http.MethodGet, "/users/{id}/posts", nil))
+	assert.Equal(t, "post", chimera.OperationIDFromPath(http.MethodPost, "/", nil))
+	assert.Equal(t, "deleteItemSubItems", chimera.OperationIDFromPath(http.MethodDelete, "/item-sub/items", nil))
+	// words can start with multi-byte letters
+	assert.Equal(t, "getÉtatsByÜber", chimera.OperationIDFromPath(http.MethodGet, "/états/{über}", nil))
+	assert.Equal(t, "ListTestOperations", chimera.OperationIDFromHandler(http.MethodGet, "/", ListTestOperations))
+	assert.Equal(t, "GetTestOperation", chimera.OperationIDFromHandler(http.MethodGet, "/", (&testOperationServer{}).GetTestOperation))
+	assert.Equal(t, "getOps", chimera.OperationIDFromHandler(http.MethodGet, "/ops", func() {}))
+
+	api := chimera.NewAPI()
+	route := chimera.Get(api, "/items/{id}", ListTestOperations)
+	assert.Equal(t, "getItemsById", route.OpenAPIOperationSpec().OperationID)
+	group := api.Group("/v1")
+	route = chimera.Get(group, "/items/{id}", ListTestOperations)
+	assert.Equal(t, "getV1ItemsById", route.OpenAPIOperationSpec().OperationID)
+
+	// mounted APIs get ids based on their final path unless they were set explicitly
+	sub := chimera.NewAPI()
+	generated := chimera.Get(sub, "/things", ListTestOperations)
+	explicit := chimera.Post(sub, "/things", ListTestOperations).WithOperation(chimera.Operation{OperationID: "makeThing"})
+	api.Mount("/v2", sub)
+	assert.Equal(t, "getV2Things", generated.OpenAPIOperationSpec().OperationID)
+	assert.Equal(t, "makeThing", explicit.OpenAPIOperationSpec().OperationID)
+
+	// duplicates across groups panic
+	assert.Panics(t, func() {
+		chimera.Put(group, "/other", ListTestOperations).WithOperation(chimera.Operation{OperationID: "getItemsById"})
+	})
+	assert.Panics(t, func() {
+		conflict := chimera.NewAPI()
+		chimera.Get(conflict, "/things", ListTestOperations).WithOperation(chimera.Operation{OperationID: "makeThing"})
+		api.Mount("/v3", conflict)
+	})
+	// overwriting a route keeps its id
+	assert.NotPanics(t, func() {
+		chimera.Get(api, "/items/{id}", ListTestOperations)
+	})
+
+	api = chimera.NewAPI(chimera.WithOperationIDs(chimera.OperationIDFromHandler))
+	route = chimera.Get(api.Group("/group"), "/", ListTestOperations)
+	assert.Equal(t, "ListTestOperations", route.OpenAPIOperationSpec().OperationID)
+	assert.Panics(t, func() {
+		chimera.Get(api, "/list", ListTestOperations)
+	})
+	route = chimera.Get(api, "/anonymous", func(*chimera.EmptyRequest) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+	assert.Equal(t, "getAnonymous", route.OpenAPIOperationSpec().OperationID)
+	assert.Equal(t, "getAnonymous", api.OpenAPISpec().Paths["/anonymous"].Get.OperationID)
+}
@@ -0,0 +1,197 @@
+package chimera
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	// optionalExtra marks the schemas of Optional fields until they are removed from required by standardizedSchemas
+	optionalExtra = "x-chimera-optional"
+)
+
+var (
+	optionalFieldType = reflect.TypeOf((*optionalField)(nil)).Elem()
+)
+
+// Optional is a value that can tell apart being absent, null or set to its zero value.
+// As a param field it is set if the param was sent (params can't be null), as a JSON body field it
+// is set if the field was present and null if it was sent as null. Use the omitzero json option (Go 1.24+)
+// to leave out unset values when writing JSON, otherwise they are written as null.
+type Optional[T any] struct {
+	value T
+	set   bool
+	null  bool
+}
+
+// optionalField gives chimera access to the value of an Optional without knowing its type
+type optionalField interface {
+	IsSet() bool
+	IsNull() bool
+	optionalValue() any
+	markSet()
+}
+
+// Some creates an Optional that is set to value
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// Null creates an Optional that is set to null
+func Null[T any]() Optional[T] {
+	return Optional[T]{set: true, null: true}
+}
+
+// IsSet checks if the value was present (including being null)
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsNull checks if the value was present and null
+func (o Optional[T]) IsNull() bool {
+	return o.set && o.null
+}
+
+// Value returns the value (the zero value of T if it is unset or null)
+func (o Optional[T]) Value() T {
+	return o.value
+}
+
+// IsZero checks if the value is unset (used by the omitzero json option)
+func (o Optional[T]) IsZero() bool {
+	return !o.set
+}
+
+// MarshalJSON writes the value or null if it is unset or null
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.set || o.null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON marks the value as set and reads it (or marks it as null)
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.set = true
+	o.null = bytes.Equal(bytes.TrimSpace(data), []byte("null"))
+	o.value = *new(T)
+	if o.null {
+		return nil
+	}
+	return json.Unmarshal(data, &o.value)
+}
+
+// optionalValue returns a pointer to the value
+func (o *Optional[T]) optionalValue() any {
+	return &o.value
+}
+
+// markSet marks the value as set (and not null)
+func (o *Optional[T]) markSet() {
+	o.set = true
+	o.null = false
+}
+
+// isOptional checks if a type is an Optional
+func isOptional(t reflect.Type) bool {
+	return t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(optionalFieldType)
+}
+
+// optionalSchema returns the schema of an Optional type (the schema of its value or null) using reflector,
+// nil is returned for other types
+func optionalSchema(reflector jsonschema.Reflector, t reflect.Type) *jsonschema.Schema {
+	if !isOptional(t) {
+		return nil
+	}
+	inner := newReflector(reflector).Reflect(reflect.New(t).Interface().(optionalField).optionalValue())
+	inner.Version = ""
+	inner.ID = ""
+	return &jsonschema.Schema{
+		AnyOf: []*jsonschema.Schema{
+			inner,
+			{Type: "null"},
+		},
+		Extras: map[string]any{optionalExtra: true},
+	}
+}
+
+// dropOptionalRequired removes Optional properties from the required properties of a schema (and its sub-schemas)
+// and removes the markers added by optionalSchema
+func dropOptionalRequired(schema *jsonschema.Schema) {
+	if schema.Properties != nil && len(schema.Required) > 0 {
+		required := make([]string, 0, len(schema.Required))
+		for _, name := range schema.Required {
+			if prop, ok := schema.Properties.Get(name); ok && prop != nil && prop.Extras[optionalExtra] == true {
+				continue
+			}
+			required = append(required, name)
+		}
+		schema.Required = required
+	}
+	forEachSubSchema(schema, dropOptionalRequired)
+	delete(schema.Extras, optionalExtra)
+	if len(schema.Extras) == 0 {
+		schema.Extras = nil
+	}
+}
+
+// setOptionalPresent marks the Optional at addr (a pointer to an Optional field) as set and returns a pointer to its value
+func setOptionalPresent(addr reflect.Value) reflect.Value {
+	opt := fixPointer(addr).Interface().(optionalField)
+	opt.markSet()
+	return reflect.ValueOf(opt.optionalValue())
+}
+
+// optionalParamValue returns a pointer to the value of the Optional at addr, false is returned if it is unset or null
+func optionalParamValue(addr reflect.Value) (reflect.Value, bool) {
+	opt := addr.Interface().(optionalField)
+	if !opt.IsSet() || opt.IsNull() {
+		return addr, false
+	}
+	return derefParam(reflect.ValueOf(opt.optionalValue()))
+}
+
+// queryParamPresent checks if any of the query values of a param were sent
+func queryParamPresent(query url.Values, tag *ParamStructTag) bool {
+	if _, ok := query[tag.Name]; ok {
+		return true
+	}
+	for name := range tag.propMap {
+		if _, ok := query[name]; ok {
+			return true
+		}
+	}
+	for key := range query {
+		switch {
+		case tag.schemaType == mapType && tag.Style == FormStyle:
+			if !tag.isReserved(key) {
+				return true
+			}
+		case tag.schemaType == mapType || tag.schemaType == nestedType:
+			if strings.HasPrefix(key, tag.Name+"[") || (tag.Nested == NestedDots && strings.HasPrefix(key, tag.Name+".")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headerParamPresent checks if any of the headers of a param were sent
+func headerParamPresent(header http.Header, tag *ParamStructTag) bool {
+	if tag.schemaType != mapType {
+		return len(header.Values(tag.Name)) > 0
+	}
+	prefix := strings.ToLower(tag.prefix)
+	for name := range header {
+		if len(name) > len(prefix) && strings.ToLower(name[:len(prefix)]) == prefix {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package chimera_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestOptionalParams struct {
+	Limit  chimera.Optional[int]      `param:"limit,in=query"`
+	Tags   chimera.Optional[[]string] `param:"tags,in=query,explode"`
+	Tenant chimera.Optional[string]   `param:"X-Tenant,in=header"`
+}
+
+type TestPatchUser struct {
+	Name  chimera.Optional[string] `json:"name,omitzero"`
+	Age   chimera.Optional[int]    `json:"age,omitzero"`
+	Email string                   `json:"email"`
+}
+
+func TestOptionalParamsAndBodies(t *testing.T) {
+	api := chimera.NewAPI()
+	var params TestOptionalParams
+	var body TestPatchUser
+	route := chimera.Patch(api, "/users", func(req *chimera.JSONRequest[TestPatchUser, TestOptionalParams]) (*chimera.JSONResponse[TestPatchUser, TestOptionalParams], error) {
+		params = req.Params
+		body = req.Body
+		return chimera.NewJSONResponse(req.Body, req.Params), nil
+	})
+
+	req := httptest.NewRequest(http.MethodPatch, "/users?limit=0", strings.NewReader(`{"name": null, "email": "a@b.c"}`))
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	// params
+	assert.True(t, params.Limit.IsSet())
+	assert.False(t, params.Limit.IsNull())
+	assert.Equal(t, 0, params.Limit.Value())
+	assert.False(t, params.Tags.IsSet())
+	assert.False(t, params.Tenant.IsSet())
+	// body
+	assert.True(t, body.Name.IsSet())
+	assert.True(t, body.Name.IsNull())
+	assert.False(t, body.Age.IsSet())
+	assert.JSONEq(t, `{"name": null, "email": "a@b.c"}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodPatch, "/users?tags=a&tags=b", strings.NewReader(`{"age": 0}`))
+	req.Header.Set("X-Tenant", "acme")
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, params.Limit.IsSet())
+	assert.Equal(t, []string{"a", "b"}, params.Tags.Value())
+	assert.Equal(t, "acme", params.Tenant.Value())
+	assert.Equal(t, chimera.Some(0), body.Age)
+	assert.Equal(t, "acme", w.Header().Get("X-Tenant"))
+
+	// unset and null params are not written
+	written := httptest.NewRequest(http.MethodGet, "/users", nil)
+	assert.NoError(t, chimera.MarshalRequestParams(written, &TestOptionalParams{Limit: chimera.Some(0), Tenant: chimera.Null[string]()}))
+	assert.Equal(t, "limit=0", written.URL.RawQuery)
+	assert.Empty(t, written.Header.Get("X-Tenant"))
+
+	// optional fields are not required and can be null
+	op := route.OpenAPIOperationSpec()
+	for _, param := range op.Parameters {
+		assert.False(t, param.Required)
+		if param.Name == "limit" {
+			assert.Equal(t, "integer", string(param.Schema.Type))
+		}
+	}
+	spec, err := json.Marshal(op.RequestBody.Content["application/json"].Schema)
+	assert.NoError(t, err)
+	components, err := json.Marshal(api.OpenAPISpec().Components.Schemas["TestPatchUser"])
+	assert.NoError(t, err)
+	assert.Contains(t, string(spec), "TestPatchUser")
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"anyOf": [{"type": "string"}, {"type": "null"}]},
+			"age": {"anyOf": [{"type": "integer"}, {"type": "null"}]},
+			"email": {"type": "string"}
+		},
+		"additionalProperties": false,
+		"required": ["email"]
+	}`, string(components))
+}
@@ -1,11 +1,14 @@
 package chimera
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/invopop/jsonschema"
@@ -20,6 +23,9 @@ const (
 	primitiveType
 	sliceType
 	interfaceType
+	encodedType
+	mapType
+	nestedType
 )
 
 // Style denotes the openapi "style" of a parameter
@@ -75,13 +81,42 @@ type ParamStructTag struct {
 	Deprecated      bool   `structtag:"deprecated"`
 	AllowEmptyValue bool   `structtag:"allowEmptyValue"`
 	AllowReserved   bool   `structtag:"allowReserved"`
-	prefix          string
-	delim           string
-	valueDelim      string
-	schemaType      SchemaType
-	propMap         map[string]*paramProp
-	schema          *jsonschema.Schema
-	request         bool
+	Example         string `structtag:"example"`
+	// response cookie attributes
+	HTTPOnly bool     `structtag:"httpOnly"`
+	Secure   bool     `structtag:"secure"`
+	SameSite SameSite `structtag:"sameSite"`
+	Path     string   `structtag:"path"`
+	Domain   string   `structtag:"domain"`
+	MaxAge   int      `structtag:"maxAge"`
+	Expires  string   `structtag:"expires"`
+	// signed/encrypted cookies (see CookieKeyRing), their values never expire on their own
+	Signed    bool `structtag:"signed"`
+	Encrypted bool `structtag:"encrypted"`
+	// Encoding is how header/cookie values are encoded (json, base64, base64json or jwt), jwt request params
+	// are rejected unless the API verifies tokens (see WithJWTVerifier and WithUnverifiedJWT)
+	Encoding string `structtag:"encoding"`
+	// Nested is the syntax of nested deepObject query params (brackets or dots)
+	Nested string `structtag:"nested"`
+	// Layout is the layout of time.Time params (defaults to RFC 3339)
+	Layout string `structtag:"layout"`
+
+	expires    time.Duration
+	prefix     string
+	delim      string
+	valueDelim string
+	schemaType SchemaType
+	propMap    map[string]*paramProp
+	schema     *jsonschema.Schema
+	request    bool
+	// reserved are the names of the other query params (exploded form map params get every other query value)
+	reserved map[string]struct{}
+	// reservedPrefixes are the key prefixes of the other query params (i.e. filter[ for deepObject and nested params)
+	reservedPrefixes []string
+	// optional is true for Optional fields (the rest of the tag describes the value of the Optional)
+	optional bool
+	// verifyJWT verifies the token of a jwt encoded param (see WithJWTVerifier)
+	verifyJWT func(token string) error
 }
 
 // OpenAPIParameterSpec returns the Parameter definition of a struct tag
@@ -108,7 +143,69 @@ func (p *ParamStructTag) OpenAPIParameterSpec() Parameter {
 		AllowEmptyValue: p.AllowEmptyValue,
 		AllowReserved:   p.AllowReserved,
 		Schema:          p.schema,
+		Example:         parseExample(p.Example, p.schema),
+	}
+}
+
+// ParamError describes a single parameter that failed to unmarshal
+type ParamError struct {
+	In     string `json:"in"`
+	Name   string `json:"name"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Error returns the string representation of the error
+func (p ParamError) Error() string {
+	if p.Value != "" {
+		return fmt.Sprintf("%s parameter %s %s: %v", p.In, p.Name, p.Reason, p.Value)
+	}
+	return fmt.Sprintf("%s parameter %s %s", p.In, p.Name, p.Reason)
+}
+
+// ParamsError is a collection of every ParamError found while unmarshaling params
+// and is written as a single 422 response
+type ParamsError []ParamError
+
+// Error returns the string representation of the errors
+func (p ParamsError) Error() string {
+	msgs := make([]string, len(p))
+	for i, e := range p {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// APIError converts the errors to a 422 APIError with a json body of the form {"errors": [...]}
+func (p ParamsError) APIError() APIError {
+	body, _ := json.Marshal(struct {
+		Errors []ParamError `json:"errors"`
+	}{
+		Errors: p,
+	})
+	return APIError{
+		StatusCode: http.StatusUnprocessableEntity,
+		Body:       body,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+	}
+}
+
+// collect adds err to the list if it describes one or more params, otherwise it returns false
+func (p *ParamsError) collect(err error) bool {
+	switch e := err.(type) {
+	case APIError:
+		if e.param == nil {
+			return false
+		}
+		*p = append(*p, *e.param)
+	case ParamError:
+		*p = append(*p, e)
+	case ParamsError:
+		*p = append(*p, e...)
+	default:
+		return false
 	}
+	return true
 }
 
 // NewRequiredParamError returns an APIError to denote that a parameter was missing
@@ -116,6 +213,11 @@ func NewRequiredParamError(in, name string) APIError {
 	return APIError{
 		StatusCode: http.StatusUnprocessableEntity,
 		Body:       []byte(fmt.Sprintf("missing required %s parameter %s", in, name)),
+		param: &ParamError{
+			In:     in,
+			Name:   name,
+			Reason: "is required",
+		},
 	}
 }
 
@@ -124,6 +226,12 @@ func NewInvalidParamError(in, paramName, value string) APIError {
 	return APIError{
 		StatusCode: http.StatusUnprocessableEntity,
 		Body:       []byte(fmt.Sprintf("%s parameter %s was improperly formatted %v", in, paramName, value)),
+		param: &ParamError{
+			In:     in,
+			Name:   paramName,
+			Value:  value,
+			Reason: "was improperly formatted",
+		},
 	}
 }
 
@@ -228,7 +336,7 @@ func initTagCache[T any](tag string) *spectagular.StructTagCache[T] {
 // paramProp stores information about a property in a param struct
 type paramProp struct {
 	schemaType SchemaType
-	fieldIndex int
+	index      []int
 }
 
 // paramPropTag is just an easy way to get the first part of a struct tag
@@ -237,8 +345,8 @@ type paramPropTag struct {
 }
 
 var (
-	requestParamTagCache  = initTagCache[ParamStructTag]("param")
-	responseParamTagCache = initTagCache[ParamStructTag]("param")
+	requestParamTagCache  = newParamTagCache()
+	responseParamTagCache = newParamTagCache()
 )
 
 // fixPointer initializes pointer objects and returns the lowest level one
@@ -263,6 +371,17 @@ func fixPointer(value reflect.Value) reflect.Value {
 	return value
 }
 
+// derefParam follows the pointers of a param without allocating anything and returns false if one is nil
+func derefParam(addr reflect.Value) (reflect.Value, bool) {
+	for addr.Elem().Kind() == reflect.Pointer {
+		if addr.Elem().IsNil() {
+			return addr, false
+		}
+		addr = addr.Elem()
+	}
+	return addr, true
+}
+
 // CacheRequestParamsType adds a type to the internal tag cache and returns the resulting Paramter objects
 func CacheRequestParamsType(t reflect.Type) []Parameter {
 	var params []Parameter
@@ -271,12 +390,17 @@ func CacheRequestParamsType(t reflect.Type) []Parameter {
 		panic("chimera: failed to parse parameter field of type " + t.Name())
 	}
 	v := reflect.New(t)
+	examples := typeExamples(t)
 	for i, tag := range pTag {
 		if tag.Value.Name == spectagular.EmptyTag || tag.Value.Name == spectagular.SkipTag {
 			continue
 		}
-		t := v.Elem().Field(tag.FieldIndex).Addr()
+		t := paramField(v.Elem(), tag.index).Addr()
 		t = fixPointer(t)
+		if opt, ok := t.Interface().(optionalField); ok {
+			tag.Value.optional = true
+			t = fixPointer(reflect.ValueOf(opt.optionalValue()))
+		}
 		switch tag.Value.In {
 		case PathIn:
 			tag.Value.Style = normalizePathStyle(Style(tag.Value.Style))
@@ -285,25 +409,29 @@ func CacheRequestParamsType(t reflect.Type) []Parameter {
 				tag.Value.schemaType = interfaceType
 			}
 		case QueryIn:
+			if tag.Value.Style == DefaultStyle && (tag.Value.Nested != "" || (tag.Value.Encoding == "" && t.Elem().Kind() == reflect.Map)) {
+				// free-form maps and nested params use name[key]=value by default
+				tag.Value.Style = DeepObjectStyle
+			}
 			tag.Value.Style = normalizeQueryStyle(Style(tag.Value.Style))
 			if t.Type().Implements(queryParamUnmarshalerType) {
 				tag.Value.schemaType = interfaceType
 			}
 		case HeaderIn:
-			// TODO: support JSON/JWT/base64
 			tag.Value.Style = SimpleStyle
 			if t.Type().Implements(headerParamUnmarshalerType) {
 				tag.Value.schemaType = interfaceType
 			}
 		case CookieIn:
-			// TODO: support JSON/JWT/base64
 			tag.Value.Style = FormStyle
 			if t.Type().Implements(cookieParamUnmarshalerType) {
 				tag.Value.schemaType = interfaceType
 			}
 		}
 		if tag.Value.schemaType != interfaceType {
-			if t.Elem().Kind() == reflect.Slice {
+			if _, ok := scalarFor(t.Elem().Type()); ok {
+				tag.Value.schemaType = primitiveType
+			} else if t.Elem().Kind() == reflect.Slice {
 				tag.Value.schemaType = sliceType
 			} else if t.Elem().Kind() == reflect.Struct {
 				tag.Value.schemaType = structType
@@ -311,9 +439,17 @@ func CacheRequestParamsType(t reflect.Type) []Parameter {
 				tag.Value.schemaType = primitiveType
 			}
 		}
+		if tag.Value.Encoding != "" && tag.Value.schemaType != interfaceType {
+			tag.Value.initEncoding(t)
+		}
+		if tag.Value.schemaType != interfaceType && tag.Value.Nested != "" {
+			tag.Value.initNested(t)
+		} else if tag.Value.schemaType != interfaceType && tag.Value.Encoding == "" && t.Elem().Kind() == reflect.Map {
+			tag.Value.initMap(t)
+		}
 		switch tag.Value.schemaType {
 		case primitiveType:
-			tag.Value.schema = (&jsonschema.Reflector{
+			tag.Value.schema = newParamReflector(tag.Value.Layout, jsonschema.Reflector{
 				ExpandedStruct: false,
 				DoNotReference: true,
 				FieldNameTag:   "prop",
@@ -326,27 +462,23 @@ func CacheRequestParamsType(t reflect.Type) []Parameter {
 			}
 		case structType:
 			tag.Value.propMap = make(map[string]*paramProp)
-			schema := (&jsonschema.Reflector{
+			schema := newParamReflector(tag.Value.Layout, jsonschema.Reflector{
 				ExpandedStruct: true,
 				FieldNameTag:   "prop",
 			}).Reflect(t.Elem().Interface())
 			tag.Value.schema = schema
-			fieldTags, _ := spectagular.ParseTagsForType[paramPropTag]("prop", t.Elem().Type())
-			for _, ft := range fieldTags {
+			for _, ft := range paramPropFields(t.Elem().Type()) {
 				if tag.Value.Name == spectagular.EmptyTag || tag.Value.Name == spectagular.SkipTag {
 					continue
 				}
-				v := t.Elem().Field(ft.FieldIndex).Addr()
+				v := paramField(t.Elem(), ft.index).Addr()
 				v = fixPointer(v)
-				field := t.Elem().Type().Field(ft.FieldIndex)
-				if field.Anonymous {
-					continue
-				}
+				field := t.Elem().Type().FieldByIndex(ft.index)
 				jsonSchemaTags := strings.Split(field.Tag.Get("jsonschema"), ",")
 				if jsonSchemaTags[0] == "-" {
 					continue
 				}
-				name := ft.Value.Name
+				name := ft.name
 				// should we only unmarshal props that are in the spec?
 				if _, ok := schema.Properties.Get(name); !ok {
 					continue
@@ -355,12 +487,12 @@ func CacheRequestParamsType(t reflect.Type) []Parameter {
 					name = tag.Value.Name + "[" + name + "]"
 				}
 				tag.Value.propMap[name] = &paramProp{
-					fieldIndex: ft.FieldIndex,
+					index: ft.index,
 				}
 				// if v.Type().Implements(paramPropUnmarshalerType) {
 				// 	tag.Value.propMap[name].schemaType = Interface
 				// } else
-				if v.Elem().Kind() != reflect.Struct &&
+				if isScalarType(v.Elem().Type()) || v.Elem().Kind() != reflect.Struct &&
 					v.Elem().Kind() != reflect.Array &&
 					v.Elem().Kind() != reflect.Slice &&
 					v.Elem().Kind() != reflect.Chan &&
@@ -408,7 +540,7 @@ func CacheRequestParamsType(t reflect.Type) []Parameter {
 				}
 			}
 		case sliceType:
-			tag.Value.schema = (&jsonschema.Reflector{
+			tag.Value.schema = newParamReflector(tag.Value.Layout, jsonschema.Reflector{
 				ExpandedStruct: false,
 				FieldNameTag:   "prop",
 			}).Reflect(t.Interface())
@@ -456,7 +588,29 @@ func CacheRequestParamsType(t reflect.Type) []Parameter {
 		}
 		tag.Value.request = true
 		pTag[i] = tag
-		params = append(params, tag.Value.OpenAPIParameterSpec())
+		param := tag.Value.OpenAPIParameterSpec()
+		if example, ok := examples[tag.Value.Name]; ok {
+			param.Example = example
+		}
+		params = append(params, param)
+	}
+	for i, tag := range pTag {
+		if tag.Value.schemaType != mapType || tag.Value.In != QueryIn || tag.Value.Style != FormStyle {
+			continue
+		}
+		pTag[i].Value.reserved = make(map[string]struct{})
+		for j, other := range pTag {
+			if other.Value.In == QueryIn && j != i {
+				pTag[i].Value.reserved[other.Value.Name] = struct{}{}
+				for name := range other.Value.propMap {
+					pTag[i].Value.reserved[name] = struct{}{}
+				}
+				pTag[i].Value.reservedPrefixes = append(pTag[i].Value.reservedPrefixes, other.Value.Name+"[")
+				if other.Value.Nested == NestedDots {
+					pTag[i].Value.reservedPrefixes = append(pTag[i].Value.reservedPrefixes, other.Value.Name+".")
+				}
+			}
+		}
 	}
 	return params
 }
@@ -472,42 +626,70 @@ func UnmarshalParams(request *http.Request, obj any) error {
 		paramTags, _ = requestParamTagCache.GetOrAdd(paramType)
 	}
 	reqCtx := chi.RouteContext(request.Context())
+	keys := cookieKeysFromContext(request.Context())
+	var errs ParamsError
 	for _, tag := range paramTags {
 		if tag.Value.Name == spectagular.EmptyTag || tag.Value.Name == spectagular.SkipTag {
 			continue
 		}
-		addr := value.Field(tag.FieldIndex).Addr()
+		addr := paramField(value, tag.index).Addr()
+		if tag.Value.optional {
+			present := true
+			switch tag.Value.In {
+			case QueryIn:
+				present = queryParamPresent(request.URL.Query(), &tag.Value)
+			case HeaderIn:
+				present = headerParamPresent(request.Header, &tag.Value)
+			case CookieIn:
+				_, cookieErr := request.Cookie(tag.Value.Name)
+				present = cookieErr == nil
+			}
+			// missing optional params are left unset (required ones still fail below)
+			if !present && !tag.Value.Required {
+				continue
+			}
+			addr = setOptionalPresent(addr)
+		}
+		var err error
 		switch tag.Value.In {
 		case PathIn:
-			if err := unmarshalPathParam(reqCtx.URLParam(tag.Value.Name), &tag.Value, addr); err != nil {
-				return err
+			param := reqCtx.URLParam(tag.Value.Name)
+			// chi matches against the raw path when there is one (i.e. for %2F) so its params are still escaped
+			if request.URL.RawPath != "" {
+				if unescaped, unescapeErr := url.PathUnescape(param); unescapeErr == nil {
+					param = unescaped
+				}
 			}
+			err = unmarshalPathParam(param, &tag.Value, addr)
 		case HeaderIn:
-			if err := unmarshalHeaderParam(request.Header.Values(tag.Value.Name), &tag.Value, addr); err != nil {
-				return err
+			if tag.Value.schemaType == mapType {
+				err = unmarshalMapFromHeader(request.Header, &tag.Value, fixPointer(addr))
+				break
 			}
+			err = unmarshalHeaderParam(request.Header.Values(tag.Value.Name), tag.Value.withJWTVerifier(request.Context()), addr)
 		case CookieIn:
-			cookie, err := request.Cookie(tag.Value.Name)
-			if err != nil {
-				if err == http.ErrNoCookie && tag.Value.Required {
-					return NewRequiredParamError("cookie", tag.Value.Name)
+			cookie, cookieErr := request.Cookie(tag.Value.Name)
+			if cookieErr == http.ErrNoCookie || cookie == nil {
+				if tag.Value.Required {
+					err = NewRequiredParamError("cookie", tag.Value.Name)
 				}
-				return err
-			}
-			if cookie != nil {
+			} else if cookieErr != nil {
+				err = cookieErr
+			} else {
 				// TODO: support raw cookie parsing? not sure how useful that is
-				if err := unmarshalCookieParam(*cookie, &tag.Value, addr); err != nil {
-					return err
-				}
-			} else if tag.Value.Required {
-				return NewRequiredParamError("cookie", tag.Value.Name)
+				err = unmarshalCookieParam(*cookie, tag.Value.withJWTVerifier(request.Context()), addr, keys)
 			}
 		case QueryIn:
-			if err := unmarshalQueryParam(request.URL.Query(), &tag.Value, addr); err != nil {
-				return err
-			}
+			err = unmarshalQueryParam(request.URL.Query(), &tag.Value, addr)
+		}
+		// errors that arent about a specific param (i.e. from custom unmarshalers) are returned as is
+		if err != nil && !errs.collect(err) {
+			return err
 		}
 	}
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
@@ -519,33 +701,45 @@ func CacheResponseParamsType(t reflect.Type) []Parameter {
 		panic("chimera: failed to parse parameter field of type " + t.Name())
 	}
 	v := reflect.New(t)
-	cookieCount := 0
+	examples := typeExamples(t)
+	setCookie := -1
 	for i, tag := range pTag {
 		if tag.Value.Name == spectagular.EmptyTag || tag.Value.Name == spectagular.SkipTag {
 			continue
 		}
-		t := v.Elem().Field(tag.FieldIndex).Addr()
+		t := paramField(v.Elem(), tag.index).Addr()
 		t = fixPointer(t)
+		if opt, ok := t.Interface().(optionalField); ok {
+			tag.Value.optional = true
+			t = fixPointer(reflect.ValueOf(opt.optionalValue()))
+		}
 		switch tag.Value.In {
 		case PathIn:
 		case QueryIn:
 			// TODO: maybe throw an error here? not sure how strict I should be
 			continue
 		case HeaderIn:
-			// TODO: support JSON/JWT/base64
 			tag.Value.Style = SimpleStyle
 			if t.Type().Implements(headerParamMarshalerType) {
 				tag.Value.schemaType = interfaceType
 			}
 		case CookieIn:
-			// TODO: support JSON/JWT/base64
 			tag.Value.Style = FormStyle
 			if t.Type().Implements(cookieParamMarshalerType) {
 				tag.Value.schemaType = interfaceType
 			}
+			if tag.Value.Expires != "" {
+				expires, err := time.ParseDuration(tag.Value.Expires)
+				if err != nil {
+					panic("chimera: invalid expires for cookie " + tag.Value.Name + " of type " + t.Type().Elem().Name())
+				}
+				tag.Value.expires = expires
+			}
 		}
 		if tag.Value.schemaType != interfaceType {
-			if t.Elem().Kind() == reflect.Slice {
+			if _, ok := scalarFor(t.Elem().Type()); ok {
+				tag.Value.schemaType = primitiveType
+			} else if t.Elem().Kind() == reflect.Slice {
 				tag.Value.schemaType = sliceType
 			} else if t.Elem().Kind() == reflect.Struct {
 				tag.Value.schemaType = structType
@@ -553,9 +747,15 @@ func CacheResponseParamsType(t reflect.Type) []Parameter {
 				tag.Value.schemaType = primitiveType
 			}
 		}
+		if tag.Value.Encoding != "" && tag.Value.schemaType != interfaceType {
+			tag.Value.initEncoding(t)
+		}
+		if tag.Value.schemaType != interfaceType && tag.Value.Encoding == "" && t.Elem().Kind() == reflect.Map {
+			tag.Value.initMap(t)
+		}
 		switch tag.Value.schemaType {
 		case primitiveType:
-			tag.Value.schema = (&jsonschema.Reflector{
+			tag.Value.schema = newParamReflector(tag.Value.Layout, jsonschema.Reflector{
 				ExpandedStruct: false,
 				DoNotReference: true,
 				FieldNameTag:   "prop",
@@ -565,27 +765,23 @@ func CacheResponseParamsType(t reflect.Type) []Parameter {
 			if tag.Value.Style == DeepObjectStyle {
 				tag.Value.Explode = true
 			}
-			schema := (&jsonschema.Reflector{
+			schema := newParamReflector(tag.Value.Layout, jsonschema.Reflector{
 				ExpandedStruct: true,
 				FieldNameTag:   "prop",
 			}).Reflect(t.Elem().Interface())
 			tag.Value.schema = schema
-			fieldTags, _ := spectagular.ParseTagsForType[paramPropTag]("prop", t.Elem().Type())
-			for _, ft := range fieldTags {
+			for _, ft := range paramPropFields(t.Elem().Type()) {
 				if tag.Value.Name == spectagular.EmptyTag || tag.Value.Name == spectagular.SkipTag {
 					continue
 				}
-				v := t.Elem().Field(ft.FieldIndex).Addr()
+				v := paramField(t.Elem(), ft.index).Addr()
 				v = fixPointer(v)
-				field := t.Elem().Type().Field(ft.FieldIndex)
-				if field.Anonymous {
-					continue
-				}
+				field := t.Elem().Type().FieldByIndex(ft.index)
 				jsonSchemaTags := strings.Split(field.Tag.Get("jsonschema"), ",")
 				if jsonSchemaTags[0] == "-" {
 					continue
 				}
-				name := ft.Value.Name
+				name := ft.name
 				// should we only unmarshal props that are in the spec?
 				if _, ok := schema.Properties.Get(name); !ok {
 					continue
@@ -594,9 +790,9 @@ func CacheResponseParamsType(t reflect.Type) []Parameter {
 					name = tag.Value.Name + "[" + name + "]"
 				}
 				tag.Value.propMap[name] = &paramProp{
-					fieldIndex: ft.FieldIndex,
+					index: ft.index,
 				}
-				if v.Elem().Kind() != reflect.Struct &&
+				if isScalarType(v.Elem().Type()) || v.Elem().Kind() != reflect.Struct &&
 					v.Elem().Kind() != reflect.Array &&
 					v.Elem().Kind() != reflect.Slice &&
 					v.Elem().Kind() != reflect.Chan &&
@@ -625,7 +821,7 @@ func CacheResponseParamsType(t reflect.Type) []Parameter {
 				}
 			}
 		case sliceType:
-			tag.Value.schema = (&jsonschema.Reflector{
+			tag.Value.schema = newParamReflector(tag.Value.Layout, jsonschema.Reflector{
 				ExpandedStruct: false,
 				FieldNameTag:   "prop",
 			}).Reflect(t.Interface())
@@ -643,10 +839,27 @@ func CacheResponseParamsType(t reflect.Type) []Parameter {
 			}
 		}
 		pTag[i] = tag
-		param := tag.Value.OpenAPIParameterSpec()
 		if tag.Value.In == CookieIn {
-			param.Name = strings.Repeat("\000", cookieCount) + param.Name
-			cookieCount++
+			// every cookie is sent in its own Set-Cookie header, since headers are documented by name
+			// they are all described by a single Set-Cookie header
+			if setCookie < 0 {
+				setCookie = len(params)
+				params = append(params, Parameter{
+					Name:   "Set-Cookie",
+					In:     marshalIn(HeaderIn),
+					Schema: &jsonschema.Schema{Type: "string"},
+				})
+			}
+			params[setCookie].Required = params[setCookie].Required || tag.Value.Required
+			if params[setCookie].Description != "" {
+				params[setCookie].Description += "\n"
+			}
+			params[setCookie].Description += tag.Value.cookieDescription()
+			continue
+		}
+		param := tag.Value.OpenAPIParameterSpec()
+		if example, ok := examples[tag.Value.Name]; ok {
+			param.Example = example
 		}
 		params = append(params, param)
 	}
@@ -656,6 +869,11 @@ func CacheResponseParamsType(t reflect.Type) []Parameter {
 // MarshalParams turns an object into headers
 // this technically supports cookies and headers but the result is all headers
 func MarshalParams(obj any) (http.Header, error) {
+	return marshalParams(obj, nil)
+}
+
+// marshalParams turns an object into headers, signed/encrypted cookies use keys
+func marshalParams(obj any, keys *CookieKeyRing) (http.Header, error) {
 	value := reflect.ValueOf(obj).Elem()
 	paramType := value.Type()
 	paramTags, found := responseParamTagCache.Get(paramType)
@@ -668,7 +886,18 @@ func MarshalParams(obj any) (http.Header, error) {
 		if tag.Value.Name == spectagular.EmptyTag || tag.Value.Name == spectagular.SkipTag {
 			continue
 		}
-		addr := value.Field(tag.FieldIndex).Addr()
+		// nil pointers are treated as missing params
+		field, ok := lookupParamField(value, tag.index)
+		if !ok {
+			continue
+		}
+		addr, ok := derefParam(field.Addr())
+		if ok && tag.Value.optional {
+			addr, ok = optionalParamValue(addr)
+		}
+		if !ok {
+			continue
+		}
 		switch tag.Value.In {
 		case HeaderIn:
 			h, err := marshalHeaderParam(&tag.Value, addr)
@@ -679,7 +908,7 @@ func MarshalParams(obj any) (http.Header, error) {
 				header[n] = append(header[n], v...)
 			}
 		case CookieIn:
-			cookie, err := marshalCookieParam(&tag.Value, addr)
+			cookie, err := marshalCookieParam(&tag.Value, addr, keys)
 			if err != nil {
 				return nil, err
 			}
@@ -688,3 +917,158 @@ func MarshalParams(obj any) (http.Header, error) {
 	}
 	return header, nil
 }
+
+// MarshalRequestParams is the inverse of UnmarshalParams and writes an object to the path, query, headers
+// and cookies of a request. Path params replace their "{name}" placeholder in request.URL.Path
+// and nil pointers are treated as missing params. Signed/encrypted cookies use the key ring of the request's context
+// (see ContextWithCookieKeys)
+func MarshalRequestParams(request *http.Request, obj any) error {
+	value := reflect.ValueOf(obj).Elem()
+	paramType := value.Type()
+	paramTags, found := requestParamTagCache.Get(paramType)
+	if !found {
+		CacheRequestParamsType(paramType)
+		paramTags, _ = requestParamTagCache.GetOrAdd(paramType)
+	}
+	if request.Header == nil {
+		request.Header = make(http.Header)
+	}
+	query := request.URL.Query()
+	keys := cookieKeysFromContext(request.Context())
+	rawPath := request.URL.RawPath
+	if rawPath == "" {
+		rawPath = request.URL.Path
+	}
+	for _, tag := range paramTags {
+		if tag.Value.Name == spectagular.EmptyTag || tag.Value.Name == spectagular.SkipTag {
+			continue
+		}
+		field, ok := lookupParamField(value, tag.index)
+		if !ok {
+			continue
+		}
+		addr, ok := derefParam(field.Addr())
+		if ok && tag.Value.optional {
+			addr, ok = optionalParamValue(addr)
+		}
+		if !ok {
+			continue
+		}
+		switch tag.Value.In {
+		case PathIn:
+			param, err := marshalPathParam(&tag.Value, addr)
+			if err != nil {
+				return err
+			}
+			// the raw path keeps escaped values (i.e. %2F) separate from the path structure
+			request.URL.Path = strings.ReplaceAll(request.URL.Path, "{"+tag.Value.Name+"}", param)
+			rawPath = strings.ReplaceAll(rawPath, "{"+tag.Value.Name+"}", url.PathEscape(param))
+		case QueryIn:
+			values, err := marshalQueryParam(&tag.Value, addr)
+			if err != nil {
+				return err
+			}
+			for k, v := range values {
+				query[k] = v
+			}
+		case HeaderIn:
+			h, err := marshalRequestHeaderParam(&tag.Value, addr)
+			if err != nil {
+				return err
+			}
+			for k, v := range h {
+				for _, x := range v {
+					request.Header.Add(k, x)
+				}
+			}
+		case CookieIn:
+			cookie, err := marshalRequestCookieParam(&tag.Value, addr, keys)
+			if err != nil {
+				return err
+			}
+			request.AddCookie(&cookie)
+		}
+	}
+	request.URL.RawPath = ""
+	if rawPath != request.URL.EscapedPath() {
+		request.URL.RawPath = rawPath
+	}
+	request.URL.RawQuery = query.Encode()
+	return nil
+}
+
+// UnmarshalResponseParams is the inverse of MarshalParams and reads the headers and cookies of a response into an object.
+// Signed/encrypted cookies use the key ring of the context of response.Request (see ContextWithCookieKeys)
+func UnmarshalResponseParams(response *http.Response, obj any) error {
+	value := reflect.ValueOf(obj).Elem()
+	paramType := value.Type()
+	paramTags, found := responseParamTagCache.Get(paramType)
+	if !found {
+		CacheResponseParamsType(paramType)
+		paramTags, _ = responseParamTagCache.GetOrAdd(paramType)
+	}
+	var keys *CookieKeyRing
+	if response.Request != nil {
+		keys = cookieKeysFromContext(response.Request.Context())
+	}
+	var cookies []*http.Cookie
+	var errs ParamsError
+	for _, tag := range paramTags {
+		if tag.Value.Name == spectagular.EmptyTag || tag.Value.Name == spectagular.SkipTag {
+			continue
+		}
+		addr := paramField(value, tag.index).Addr()
+		if tag.Value.optional {
+			present := true
+			switch tag.Value.In {
+			case HeaderIn:
+				present = headerParamPresent(response.Header, &tag.Value)
+			case CookieIn:
+				if cookies == nil {
+					cookies = response.Cookies()
+				}
+				present = false
+				for _, c := range cookies {
+					present = present || c.Name == tag.Value.Name
+				}
+			}
+			if !present && !tag.Value.Required {
+				continue
+			}
+			addr = setOptionalPresent(addr)
+		}
+		var err error
+		switch tag.Value.In {
+		case HeaderIn:
+			if tag.Value.schemaType == mapType {
+				err = unmarshalMapFromHeader(response.Header, &tag.Value, fixPointer(addr))
+			} else if values := response.Header.Values(tag.Value.Name); len(values) > 0 {
+				err = unmarshalResponseHeaderParam(values, &tag.Value, addr)
+			} else if tag.Value.Required {
+				err = NewRequiredParamError("header", tag.Value.Name)
+			}
+		case CookieIn:
+			if cookies == nil {
+				cookies = response.Cookies()
+			}
+			var cookie *http.Cookie
+			for _, c := range cookies {
+				if c.Name == tag.Value.Name {
+					cookie = c
+				}
+			}
+			if cookie != nil {
+				err = unmarshalResponseCookieParam(*cookie, &tag.Value, addr, keys)
+			} else if tag.Value.Required {
+				err = NewRequiredParamError("cookie", tag.Value.Name)
+			}
+		}
+		if err != nil && !errs.collect(err) {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
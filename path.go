@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -22,7 +23,6 @@ func normalizePathStyle(style Style) Style {
 
 // decodePrimitiveString turns a string into a primitive reflect value
 func decodePrimitiveString(value string, kind reflect.Kind) (reflect.Value, error) {
-	// TODO: support time, ip, email, url, etc?
 	switch kind {
 	case reflect.Bool:
 		v, err := strconv.ParseBool(value)
@@ -110,6 +110,8 @@ func unmarshalStringParam(param string, tag *ParamStructTag, addr reflect.Value)
 		return unmarshalPrimitiveFromString(param, tag, addr)
 	case structType:
 		return unmarshalStructFromString(param, tag, addr)
+	case encodedType:
+		return unmarshalEncodedParam(param, tag, addr)
 	}
 	return nil
 }
@@ -120,7 +122,7 @@ func unmarshalPrimitiveFromString(param string, tag *ParamStructTag, addr reflec
 	if err != nil {
 		return err
 	}
-	val, err := decodePrimitiveString(param, addr.Elem().Kind())
+	val, err := decodeParamString(param, addr.Elem().Type(), tag.Layout)
 	addr.Elem().Set(val)
 	if err != nil {
 		err = NewInvalidParamError(marshalIn(tag.In), tag.Name, param)
@@ -138,7 +140,7 @@ func unmarshalSliceFromString(param string, tag *ParamStructTag, addr reflect.Va
 		return err
 	}
 	for _, v := range strings.Split(param, tag.delim) {
-		val, err := decodePrimitiveString(v, eType.Kind())
+		val, err := decodeParamString(v, eType, tag.Layout)
 		if err != nil {
 			return NewInvalidParamError(marshalIn(tag.In), tag.Name, param)
 		}
@@ -153,6 +155,74 @@ type PathParamUnmarshaler interface {
 	UnmarshalPathParam(param string, info ParamStructTag) error
 }
 
+// PathParamMarshaler is used to allow types to implement their own logic for writing path parameters
+type PathParamMarshaler interface {
+	MarshalPathParam(info ParamStructTag) (string, error)
+}
+
+// marshalPathParam converts a value into a path param (string)
+func marshalPathParam(tag *ParamStructTag, addr reflect.Value) (string, error) {
+	if tag.schemaType == interfaceType {
+		if m, ok := addr.Interface().(PathParamMarshaler); ok {
+			return m.MarshalPathParam(*tag)
+		}
+		return "", fmt.Errorf("chimera: path parameter %s does not implement PathParamMarshaler", tag.Name)
+	}
+	return marshalStringParam(tag, addr), nil
+}
+
+// marshalStringParam is the inverse of unmarshalStringParam and converts a value to a string
+func marshalStringParam(tag *ParamStructTag, addr reflect.Value) string {
+	switch tag.schemaType {
+	case sliceType:
+		values := make([]string, addr.Elem().Len())
+		for i := range values {
+			values[i] = encodeParamString(addr.Elem().Index(i).Addr(), tag.Layout)
+		}
+		return tag.prefix + strings.Join(values, tag.delim)
+	case structType:
+		return tag.prefix + strings.Join(marshalStructProps(tag, addr), tag.delim)
+	case encodedType:
+		value, _ := marshalEncodedParam(tag, addr)
+		return value
+	}
+	return tag.prefix + encodeParamString(addr, tag.Layout)
+}
+
+// marshalStructProps converts the props of a struct to a list of name/value pairs joined by tag.valueDelim
+func marshalStructProps(tag *ParamStructTag, addr reflect.Value) []string {
+	names, values := structProps(tag, addr)
+	for i := range values {
+		values[i] = names[i] + tag.valueDelim + values[i]
+	}
+	return values
+}
+
+// structProps gets the prop names (sorted) and values of a struct param, nil props are skipped
+func structProps(tag *ParamStructTag, addr reflect.Value) ([]string, []string) {
+	names := make([]string, 0, len(tag.propMap))
+	for name := range tag.propMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make([]string, 0, len(names))
+	i := 0
+	for _, name := range names {
+		field, ok := lookupParamField(addr.Elem(), tag.propMap[name].index)
+		if !ok {
+			continue
+		}
+		f, ok := derefParam(field.Addr())
+		if !ok {
+			continue
+		}
+		names[i] = name
+		values = append(values, encodeParamString(f, tag.Layout))
+		i++
+	}
+	return names[:i], values
+}
+
 // type ParamPropUnmarshaler interface {
 // 	UnmarshalParamProp(prop string) error
 // }
@@ -205,10 +275,10 @@ func unmarshalStructFromString(param string, tag *ParamStructTag, addr reflect.V
 	}
 	for name, prop := range tag.propMap {
 		if valStr, ok := props[name]; ok {
-			f := addr.Elem().Field(prop.fieldIndex)
+			f := paramField(addr.Elem(), prop.index)
 			switch prop.schemaType {
 			case primitiveType:
-				v, err := decodePrimitiveString(valStr, f.Kind())
+				v, err := decodeParamString(valStr, f.Type(), tag.Layout)
 				if err != nil {
 					return NewInvalidParamError(marshalIn(tag.In), tag.Name, param)
 				}
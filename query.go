@@ -1,6 +1,7 @@
 package chimera
 
 import (
+	"fmt"
 	"net/url"
 	"reflect"
 	"strings"
@@ -15,14 +16,54 @@ type QueryParamUnmarshaler interface {
 	UnmarshalQueryParam(value url.Values, info ParamStructTag) error
 }
 
+// QueryParamMarshaler allows a type to add custom logic for writing query parameters
+type QueryParamMarshaler interface {
+	MarshalQueryParam(info ParamStructTag) (url.Values, error)
+}
+
+// marshalQueryParam is the inverse of unmarshalQueryParam and converts a value to query values
+func marshalQueryParam(tag *ParamStructTag, addr reflect.Value) (url.Values, error) {
+	values := make(url.Values)
+	switch tag.schemaType {
+	case interfaceType:
+		if m, ok := addr.Interface().(QueryParamMarshaler); ok {
+			return m.MarshalQueryParam(*tag)
+		}
+		return nil, fmt.Errorf("chimera: query parameter %s does not implement QueryParamMarshaler", tag.Name)
+	case primitiveType:
+		values.Set(tag.Name, encodeParamString(addr, tag.Layout))
+	case sliceType:
+		elems := make([]string, addr.Elem().Len())
+		for i := range elems {
+			elems[i] = encodeParamString(addr.Elem().Index(i).Addr(), tag.Layout)
+		}
+		if tag.Explode {
+			values[tag.Name] = elems
+		} else {
+			values.Set(tag.Name, strings.Join(elems, tag.delim))
+		}
+	case structType:
+		if tag.Style == FormStyle && !tag.Explode {
+			values.Set(tag.Name, strings.Join(marshalStructProps(tag, addr), tag.delim))
+			break
+		}
+		// exploded form and deepObject styles use one query value per prop
+		names, props := structProps(tag, addr)
+		for i, name := range names {
+			values.Set(name, props[i])
+		}
+	case mapType:
+		return marshalMapToQuery(tag, addr), nil
+	case nestedType:
+		return marshalNestedToQuery(tag, addr), nil
+	}
+	return values, nil
+}
+
 // unmarshalQueryParam attempts to turn query values into a value
 func unmarshalQueryParam(param url.Values, tag *ParamStructTag, addr reflect.Value) error {
-	// TODO: we need to find a way to handle multiple nested query params
-	// its hard because form encoded bodies cant repeat keys but query strings
-	// are the wild west. at this point, I am just going to follow the OpenAPI
-	// standard but it may be preferable later to support multiple options like
-	// X.Y.Z.0 or X[Y][Z][0] or X.Y.Z[0] or just always treat repeats as arrays
-	// may also want to disable the use of explode for form styles
+	// NOTE: single level structs follow the OpenAPI standard, multiple levels
+	// of nesting (X[Y][Z][0] or X.Y.Z[0]) are opt-in using the nested option
 	addr = fixPointer(addr)
 	if tag.schemaType == interfaceType {
 		return addr.Interface().(QueryParamUnmarshaler).UnmarshalQueryParam(param, *tag)
@@ -40,6 +81,10 @@ func unmarshalQueryParam(param url.Values, tag *ParamStructTag, addr reflect.Val
 		}
 	case structType:
 		return unmarshalStructFromQuery(param, tag, addr)
+	case mapType:
+		return unmarshalMapFromQuery(param, tag, addr)
+	case nestedType:
+		return unmarshalNestedFromQuery(param, tag, addr)
 	}
 	return nil
 }
@@ -61,7 +106,7 @@ func unmarshalSliceFromQuery(param url.Values, tag *ParamStructTag, addr reflect
 	}
 
 	for _, v := range vals {
-		val, err := decodePrimitiveString(v, eType.Kind())
+		val, err := decodeParamString(v, eType, tag.Layout)
 		if err != nil {
 			return NewInvalidParamError(marshalIn(tag.In), tag.Name, v)
 		}
@@ -81,8 +126,8 @@ func unmarshalStructFromQuery(param url.Values, tag *ParamStructTag, addr reflec
 				switch prop.schemaType {
 				case primitiveType:
 					if val, ok := param[name]; ok && len(val) > 0 {
-						f := addr.Elem().Field(prop.fieldIndex)
-						v, err := decodePrimitiveString(val[0], f.Kind())
+						f := paramField(addr.Elem(), prop.index)
+						v, err := decodeParamString(val[0], f.Type(), tag.Layout)
 						if err != nil {
 							return NewInvalidParamError(marshalIn(tag.In), tag.Name, val[0])
 						}
@@ -91,7 +136,7 @@ func unmarshalStructFromQuery(param url.Values, tag *ParamStructTag, addr reflec
 					}
 					// case Interface:
 					// 	if val, ok := param[name]; ok && len(val) > 0 {
-					// 		f := addr.Elem().Field(prop.fieldIndex)
+					// 		f := paramField(addr.Elem(), prop.index)
 					// 		err := f.Interface().(ParamPropUnmarshaler).UnmarshalParamProp(val[0])
 					// 		if err != nil {
 					// 			return nil
@@ -115,9 +160,9 @@ func unmarshalStructFromQuery(param url.Values, tag *ParamStructTag, addr reflec
 	case DeepObjectStyle:
 		requiredCheck := !tag.Required
 		for name, prop := range tag.propMap {
-			f := addr.Elem().Field(prop.fieldIndex)
+			f := paramField(addr.Elem(), prop.index)
 			if val, ok := param[name]; ok && len(val) > 0 {
-				v, err := decodePrimitiveString(val[0], f.Kind())
+				v, err := decodeParamString(val[0], f.Type(), tag.Layout)
 				if err != nil {
 					return NewInvalidParamError(marshalIn(tag.In), tag.Name, val[0])
 				}
@@ -1,6 +1,8 @@
 package chimera
 
 import (
+	"bytes"
+	"io"
 	"net/http"
 	"reflect"
 )
@@ -9,6 +11,9 @@ var (
 	_ RequestReader = new(EmptyRequest)
 	_ RequestReader = new(NoBodyRequest[Nil])
 	_ RequestReader = new(Request)
+	_ RequestWriter = new(EmptyRequest)
+	_ RequestWriter = new(NoBodyRequest[Nil])
+	_ RequestWriter = new(Request)
 )
 
 // RequestReader is used to allow chimera to automatically read/parse requests
@@ -25,6 +30,25 @@ type RequestReaderPtr[T any] interface {
 	*T
 }
 
+// RequestWriter is the inverse of RequestReader and is used to turn a request type back into an http.Request
+// (i.e. for tests or clients). Path params are written to their "{name}" placeholders in the URL path
+type RequestWriter interface {
+	WriteRequest(*http.Request) error
+}
+
+// setRequestBody sets the body of a request that is being written
+func setRequestBody(req *http.Request, contentType string, body []byte) {
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.ContentLength = int64(len(body))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+}
+
 // EmptyRequest is an empty request, effectively a no-op
 // (mostly used for GET requests)
 type EmptyRequest struct{}
@@ -34,6 +58,11 @@ func (*EmptyRequest) ReadRequest(*http.Request) error {
 	return nil
 }
 
+// WriteRequest does nothing
+func (*EmptyRequest) WriteRequest(*http.Request) error {
+	return nil
+}
+
 // OpenAPIRequestSpec returns an empty RequestSpec
 func (*EmptyRequest) OpenAPIRequestSpec() RequestSpec {
 	return RequestSpec{}
@@ -57,6 +86,14 @@ func (r *NoBodyRequest[Params]) ReadRequest(req *http.Request) error {
 	return nil
 }
 
+// WriteRequest writes the params to the request
+func (r *NoBodyRequest[Params]) WriteRequest(req *http.Request) error {
+	if _, ok := any(r.Params).(Nil); !ok {
+		return MarshalRequestParams(req, &r.Params)
+	}
+	return nil
+}
+
 // OpenAPIRequestSpec returns the parameter definitions of this object
 func (r *NoBodyRequest[Params]) OpenAPIRequestSpec() RequestSpec {
 	schema := RequestSpec{}
@@ -78,6 +115,22 @@ func (r *Request) ReadRequest(req *http.Request) error {
 	return nil
 }
 
+// WriteRequest copies the headers and body of the Request to req
+func (r *Request) WriteRequest(req *http.Request) error {
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+	if r.Body != nil {
+		req.Body = r.Body
+		req.ContentLength = r.ContentLength
+		req.GetBody = r.GetBody
+	}
+	return nil
+}
+
 // OpenAPIRequestSpec returns an empty RequestSpec
 func (r *Request) OpenAPIRequestSpec() RequestSpec {
 	return RequestSpec{}
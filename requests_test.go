@@ -2,6 +2,7 @@ package chimera_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -337,3 +338,65 @@ func TestOpenAPI(t *testing.T) {
 	route.Internalize()
 	assert.NotContains(t, api.OpenAPISpec().Paths, "/testopenapi")
 }
+
+func TestAggregatedParamErrors(t *testing.T) {
+	type Params struct {
+		Path   int    `param:"path,in=path"`
+		Query  bool   `param:"query,in=query,required"`
+		Header []int  `param:"header,in=header"`
+		Cookie string `param:"cookie,in=cookie,required"`
+		Valid  string `param:"valid,in=query"`
+	}
+
+	api := chimera.NewAPI()
+	params := addRequestTestHandler(t, api, http.MethodGet, "/{path}", &chimera.NoBodyRequest[Params]{})
+	server := httptest.NewServer(api)
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/notanint?valid=ok", bytes.NewBufferString(""))
+	assert.NoError(t, err)
+	req.Header.Add("header", "1,x")
+	resp, err := http.DefaultClient.Do(req)
+	server.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, resp.StatusCode, 422)
+	assert.Equal(t, resp.Header.Get("Content-Type"), "application/json")
+	assert.Nil(t, *params)
+
+	body := struct {
+		Errors []chimera.ParamError `json:"errors"`
+	}{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.ElementsMatch(t, body.Errors, []chimera.ParamError{
+		{In: "path", Name: "path", Value: "notanint", Reason: "was improperly formatted"},
+		{In: "query", Name: "query", Reason: "is required"},
+		{In: "header", Name: "header", Value: "1,x", Reason: "was improperly formatted"},
+		{In: "cookie", Name: "cookie", Reason: "is required"},
+	})
+}
+
+func testWriteRequest[ReqPtr interface {
+	chimera.RequestReaderPtr[Req]
+	chimera.RequestWriter
+}, Req any](t *testing.T, path string, expected ReqPtr) {
+	api := chimera.NewAPI()
+	actual := addRequestTestHandler(t, api, http.MethodGet, path, expected)
+	server := httptest.NewServer(api)
+	req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, expected.WriteRequest(req))
+	resp, err := http.DefaultClient.Do(req)
+	server.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, resp.StatusCode, 200)
+	assert.Equal(t, *actual, expected)
+}
+
+func TestWriteRequest(t *testing.T) {
+	testWriteRequest(t, testValidSimplePath+testValidLabelPath+testValidMatrixPath, &chimera.NoBodyRequest[TestPrimitivePathParams]{Params: testPrimitivePathParams})
+	testWriteRequest(t, testValidSimplePath+testValidLabelPath+testValidMatrixPath, &chimera.NoBodyRequest[TestComplexPathParams]{Params: testComplexPathParams})
+	testWriteRequest(t, "/headertest", &chimera.NoBodyRequest[TestPrimitiveHeaderParams]{Params: testPrimitiveHeaderParams})
+	testWriteRequest(t, "/headertest", &chimera.NoBodyRequest[TestComplexHeaderParams]{Params: testComplexHeaderParams})
+	testWriteRequest(t, "/cookietest", &chimera.NoBodyRequest[TestPrimitiveCookieParams]{Params: testPrimitiveCookieParams})
+	testWriteRequest(t, "/cookietest", &chimera.NoBodyRequest[TestComplexCookieParams]{Params: testComplexCookieParams})
+	testWriteRequest(t, "/querytest", &chimera.NoBodyRequest[TestPrimitiveQueryParams]{Params: testPrimitiveQueryParams})
+	testWriteRequest(t, "/querytest", &chimera.NoBodyRequest[TestComplexQueryParams]{Params: testComplexQueryParams})
+}
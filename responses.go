@@ -1,6 +1,7 @@
 package chimera
 
 import (
+	"io"
 	"net/http"
 	"reflect"
 )
@@ -12,12 +13,32 @@ var (
 	_ http.ResponseWriter = new(Response)
 	_ http.ResponseWriter = new(httpResponseWriter)
 	_ ResponseWriter      = new(LazyBodyResponse)
+	_ ResponseReader      = new(EmptyResponse)
+	_ ResponseReader      = new(NoBodyResponse[Nil])
+	_ ResponseReader      = new(Response)
 )
 
 // ResponseHead contains the head of an HTTP Response
 type ResponseHead struct {
 	StatusCode int
 	Headers    http.Header
+	// cookieKeys are used to sign/encrypt cookie params
+	cookieKeys *CookieKeyRing
+}
+
+// MarshalParams adds the headers and cookies of a params object to the head (like MarshalParams but
+// signed/encrypted cookies use the API's CookieKeyRing)
+func (h *ResponseHead) MarshalParams(obj any) error {
+	header, err := marshalParams(obj, h.cookieKeys)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		for _, x := range v {
+			h.Headers.Add(k, x)
+		}
+	}
+	return nil
 }
 
 type BodyWriteFunc func(body []byte) (int, error)
@@ -44,6 +65,28 @@ type ResponseWriterPtr[T any] interface {
 	*T
 }
 
+// ResponseReader is the inverse of ResponseWriter and is used to turn an http.Response back
+// into a response type (i.e. for tests or clients)
+type ResponseReader interface {
+	ReadResponse(*http.Response) error
+}
+
+// ResponseReaderPtr is just a workaround to allow chimera to accept a pointer
+// to a ResponseReader and convert to the underlying type
+type ResponseReaderPtr[T any] interface {
+	ResponseReader
+	*T
+}
+
+// readResponseBody reads and closes the body of a response
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	if resp.Body == nil {
+		return nil, nil
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
 // EmptyResponse is an empty response, effectively a no-op
 // (mostly used for DELETE requests)
 type EmptyResponse struct{}
@@ -58,6 +101,11 @@ func (*EmptyResponse) WriteBody(BodyWriteFunc) error {
 	return nil
 }
 
+// ReadResponse does nothing
+func (*EmptyResponse) ReadResponse(*http.Response) error {
+	return nil
+}
+
 // OpenAPIResponsesSpec returns an empty Responses definition
 func (*EmptyResponse) OpenAPIResponsesSpec() Responses {
 	return Responses{}
@@ -103,19 +151,18 @@ func (r *NoBodyResponse[Params]) OpenAPIResponsesSpec() Responses {
 
 // WriteHead writes the headers for this response
 func (r *NoBodyResponse[Params]) WriteHead(head *ResponseHead) error {
-	h, err := MarshalParams(&r.Params)
-	if err != nil {
-		return err
-	}
-	for k, v := range h {
-		for _, x := range v {
-			head.Headers.Add(k, x)
-		}
+	return head.MarshalParams(&r.Params)
+}
+
+// ReadResponse reads the params from the response headers
+func (r *NoBodyResponse[Params]) ReadResponse(resp *http.Response) error {
+	if _, ok := any(r.Params).(Nil); !ok {
+		return UnmarshalResponseParams(resp, &r.Params)
 	}
 	return nil
 }
 
-// NewBinaryResponse creates a NoBodyResponse from params
+// NewNoBodyResponse creates a NoBodyResponse from params
 func NewNoBodyResponse[Params any](params Params) *NoBodyResponse[Params] {
 	return &NoBodyResponse[Params]{
 		Params: params,
@@ -129,6 +176,10 @@ type httpResponseWriter struct {
 	response  ResponseWriter
 	route     *route
 	dirty     bool
+	// groupParams are the params parsed by the groups of the route (see WithGroupParams)
+	groupParams []any
+	// dependencies resolves the dependencies of the route (see API.Provide)
+	dependencies *dependencyResolver
 }
 
 // Header returns the response headers
@@ -179,6 +230,18 @@ func (r *Response) WriteHead(head *ResponseHead) error {
 	return nil
 }
 
+// ReadResponse stores the status code, headers and body of resp in the Response object
+func (r *Response) ReadResponse(resp *http.Response) error {
+	body, err := readResponseBody(resp)
+	if err != nil {
+		return err
+	}
+	r.StatusCode = resp.StatusCode
+	r.Headers = resp.Header
+	r.Body = body
+	return nil
+}
+
 // Write stores the body in the Reponse object for use later
 func (r *Response) Write(body []byte) (int, error) {
 	if r.Body == nil {
@@ -39,3 +39,30 @@ func TestNoBodyResponseValid(t *testing.T) {
 	}
 	assert.Equal(t, len(cookie), len(found))
 }
+
+func testReadResponse[RespPtr interface {
+	chimera.ResponseWriterPtr[Resp]
+	chimera.ResponseReader
+}, Resp any](t *testing.T, expected RespPtr) {
+	api := chimera.NewAPI()
+	addResponseTestHandler(t, api, http.MethodGet, "/test", expected)
+	server := httptest.NewServer(api)
+	resp, err := http.Get(server.URL + "/test")
+	server.Close()
+	assert.NoError(t, err)
+	actual := RespPtr(new(Resp))
+	assert.NoError(t, actual.ReadResponse(resp))
+	assert.Equal(t, expected, actual)
+}
+
+func TestReadResponse(t *testing.T) {
+	testReadResponse(t, &chimera.NoBodyResponse[TestPrimitiveHeaderParams]{Params: testPrimitiveHeaderParams})
+	testReadResponse(t, &chimera.NoBodyResponse[TestComplexHeaderParams]{Params: testComplexHeaderParams})
+	testReadResponse(t, &chimera.NoBodyResponse[TestPrimitiveCookieParams]{Params: testPrimitiveCookieParams})
+	testReadResponse(t, &chimera.JSONResponse[TestStructParams, TestPrimitiveHeaderParams]{
+		Body:   TestStructParams{StringProp: "test", IntProp: 1},
+		Params: testPrimitiveHeaderParams,
+	})
+	testReadResponse(t, &chimera.PlainTextResponse[chimera.Nil]{Body: "test"})
+	testReadResponse(t, &chimera.BinaryResponse[chimera.Nil]{Body: []byte("test")})
+}
@@ -14,6 +14,7 @@ type routeContext struct {
 	path         string
 	method       string
 	responseCode int
+	api          *API
 }
 
 // RouteContext contains basic info about a matched Route
@@ -51,6 +52,9 @@ func (r *routeContext) GetResponseHead(resp ResponseWriter) (*ResponseHead, erro
 		Headers:    make(http.Header),
 		StatusCode: r.responseCode,
 	}
+	if r.api != nil {
+		head.cookieKeys = r.api.cookieKeyRing()
+	}
 	err := resp.WriteHead(&head)
 	return &head, err
 }
@@ -81,7 +85,12 @@ type route struct {
 	context       *routeContext
 	defaultCode   string
 	hidden        bool
-	api           *API
+	// requiredSecurity is required by the dependencies of the route on top of its (or the default) security
+	requiredSecurity map[string][]string
+	api              *API
+	// handlerFunc and defaultOperationID are kept to regenerate the operationId when the API is mounted
+	handlerFunc        any
+	defaultOperationID string
 }
 
 // Route contains basic info about an API route and allows for inline editing of itself
@@ -112,39 +121,62 @@ func (r Route) WithResponseCode(code int) Route {
 // WithResponses performs a merge on the operation's responses for this route
 func (r Route) WithResponses(resp Responses) Route {
 	r.route.operationSpec.Responses.Merge(resp)
+	r.validateExamples()
 	return r
 }
 
 // WithRequest performs a merge on the operation's request spec for this route
 func (r Route) WithRequest(req RequestSpec) Route {
 	r.route.operationSpec.RequestSpec.Merge(req)
+	r.validateExamples()
 	return r
 }
 
 // WithOperation performs a merge on the operation's spec for this route
 func (r Route) WithOperation(op Operation) Route {
+	id := r.route.operationSpec.OperationID
 	r.route.operationSpec.Merge(op)
+	if err := findDuplicateOperationID(r.route.api); err != nil {
+		r.route.operationSpec.OperationID = id
+		panic(err.Error())
+	}
+	r.validateExamples()
 	return r
 }
 
 // UsingResponses replaces the operation's responses for this route
 func (r Route) UsingResponses(resp Responses) Route {
 	r.route.operationSpec.Responses = resp
+	r.validateExamples()
 	return r
 }
 
 // UsingRequest replaces the operation's request spec for this route
 func (r Route) UsingRequest(req RequestSpec) Route {
 	r.route.operationSpec.RequestSpec = &req
+	r.validateExamples()
 	return r
 }
 
 // UsingOperation replaces the operation's spec for this route
 func (r Route) UsingOperation(op Operation) Route {
-	r.route.operationSpec = &op
+	id := r.route.operationSpec.OperationID
+	*r.route.operationSpec = op
+	if err := findDuplicateOperationID(r.route.api); err != nil {
+		r.route.operationSpec.OperationID = id
+		panic(err.Error())
+	}
+	r.validateExamples()
 	return r
 }
 
+// validateExamples checks the examples of the route's operation after it was changed (like when the route was added)
+func (r Route) validateExamples() {
+	if err := validateOperationExamples(r.route.operationSpec, r.route.api.openAPISpec.Components.Schemas); err != nil {
+		panic("chimera: " + r.route.context.method + " " + r.route.context.path + " has " + err.Error())
+	}
+}
+
 // Internalize hides the route from the api spec
 func (r Route) Internalize() Route {
 	r.route.hidden = true
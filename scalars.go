@@ -0,0 +1,353 @@
+package chimera
+
+import (
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	// dateLayout is the layout of a full-date (RFC 3339)
+	dateLayout = "2006-01-02"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	ipType       = reflect.TypeOf(net.IP{})
+	addrType     = reflect.TypeOf(netip.Addr{})
+	urlType      = reflect.TypeOf(url.URL{})
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	schemaerType        = reflect.TypeOf((*interface{ JSONSchema() *jsonschema.Schema })(nil)).Elem()
+
+	textScalars sync.Map
+)
+
+// paramScalar describes a type that is not a primitive kind but is still read from (and written as) a single string
+type paramScalar struct {
+	decode func(value, layout string) (any, error)
+	encode func(value reflect.Value, layout string) string
+	schema func(layout string) *jsonschema.Schema
+}
+
+var (
+	paramScalars = map[reflect.Type]paramScalar{
+		timeType: {
+			decode: func(value, layout string) (any, error) {
+				return time.Parse(timeLayout(layout), value)
+			},
+			encode: func(value reflect.Value, layout string) string {
+				return value.Interface().(time.Time).Format(timeLayout(layout))
+			},
+			schema: func(layout string) *jsonschema.Schema {
+				switch timeLayout(layout) {
+				case time.RFC3339, time.RFC3339Nano:
+					return &jsonschema.Schema{Type: "string", Format: "date-time"}
+				case dateLayout:
+					return &jsonschema.Schema{Type: "string", Format: "date"}
+				}
+				return &jsonschema.Schema{Type: "string", Description: "formatted as " + layout}
+			},
+		},
+		durationType: {
+			decode: func(value, layout string) (any, error) {
+				return parseDuration(value)
+			},
+			encode: func(value reflect.Value, layout string) string {
+				return formatDuration(time.Duration(value.Int()))
+			},
+			schema: func(layout string) *jsonschema.Schema {
+				return &jsonschema.Schema{Type: "string", Format: "duration"}
+			},
+		},
+		ipType: {
+			decode: func(value, layout string) (any, error) {
+				ip := net.ParseIP(value)
+				if ip == nil {
+					return nil, errors.New("invalid IP address: " + value)
+				}
+				return ip, nil
+			},
+			encode: func(value reflect.Value, layout string) string {
+				return value.Interface().(net.IP).String()
+			},
+			schema: ipSchema,
+		},
+		addrType: {
+			decode: func(value, layout string) (any, error) {
+				return netip.ParseAddr(value)
+			},
+			encode: func(value reflect.Value, layout string) string {
+				return value.Interface().(netip.Addr).String()
+			},
+			schema: ipSchema,
+		},
+		urlType: {
+			decode: func(value, layout string) (any, error) {
+				u, err := url.Parse(value)
+				if err != nil {
+					return nil, err
+				}
+				return *u, nil
+			},
+			encode: func(value reflect.Value, layout string) string {
+				u := value.Interface().(url.URL)
+				return u.String()
+			},
+			schema: func(layout string) *jsonschema.Schema {
+				return &jsonschema.Schema{Type: "string", Format: "uri"}
+			},
+		},
+	}
+	// uuidScalar is used for every [16]byte type (i.e. github.com/google/uuid.UUID)
+	uuidScalar = paramScalar{
+		decode: func(value, layout string) (any, error) {
+			return parseUUID(value)
+		},
+		encode: func(value reflect.Value, layout string) string {
+			var uuid [16]byte
+			reflect.Copy(reflect.ValueOf(&uuid).Elem(), value)
+			return formatUUID(uuid)
+		},
+		schema: func(layout string) *jsonschema.Schema {
+			return &jsonschema.Schema{Type: "string", Format: "uuid"}
+		},
+	}
+)
+
+// ipSchema is the schema of an IPv4 or IPv6 address
+func ipSchema(layout string) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type: "string",
+		AnyOf: []*jsonschema.Schema{
+			{Format: "ipv4"},
+			{Format: "ipv6"},
+		},
+	}
+}
+
+// scalarFor gets the paramScalar of a type (if it is one)
+func scalarFor(t reflect.Type) (paramScalar, bool) {
+	if scalar, ok := paramScalars[t]; ok {
+		return scalar, true
+	}
+	if t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 {
+		return uuidScalar, true
+	}
+	return textScalar(t)
+}
+
+// textScalar gets the paramScalar of a type that implements encoding.TextUnmarshaler or encoding.TextMarshaler
+// (with either a value or pointer receiver), when writing fmt.Stringer is used if MarshalText isnt implemented
+func textScalar(t reflect.Type) (paramScalar, bool) {
+	if scalar, ok := textScalars.Load(t); ok {
+		return scalar.(paramScalar), scalar.(paramScalar).decode != nil
+	}
+	ptr := reflect.PointerTo(t)
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface || (!ptr.Implements(textUnmarshalerType) && !ptr.Implements(textMarshalerType)) {
+		textScalars.Store(t, paramScalar{})
+		return paramScalar{}, false
+	}
+	scalar := paramScalar{
+		decode: func(value, layout string) (any, error) {
+			v := reflect.New(t)
+			if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+				err := u.UnmarshalText([]byte(value))
+				return v.Elem().Interface(), err
+			}
+			if !isPrimitiveKind(t.Kind()) {
+				return nil, errors.New("chimera: " + t.String() + " does not implement encoding.TextUnmarshaler")
+			}
+			p, err := decodePrimitiveString(value, t.Kind())
+			if err != nil {
+				return nil, err
+			}
+			return p.Convert(t).Interface(), nil
+		},
+		encode: func(value reflect.Value, layout string) string {
+			v := reflect.New(t)
+			v.Elem().Set(value)
+			if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+				text, _ := m.MarshalText()
+				return string(text)
+			}
+			if s, ok := v.Interface().(fmt.Stringer); ok {
+				return s.String()
+			}
+			return fmt.Sprint(value.Interface())
+		},
+		schema: func(layout string) *jsonschema.Schema {
+			if t.Implements(schemaerType) {
+				// let the reflector use the JSONSchema override
+				return nil
+			}
+			return &jsonschema.Schema{Type: "string"}
+		},
+	}
+	textScalars.Store(t, scalar)
+	return scalar, true
+}
+
+// isScalarType checks if a type can be decoded from a single string
+func isScalarType(t reflect.Type) bool {
+	_, ok := scalarFor(t)
+	return ok || isPrimitiveKind(t.Kind())
+}
+
+// decodeParamString turns a string into a value of type t, layout is the time layout of time.Time values
+func decodeParamString(value string, t reflect.Type, layout string) (reflect.Value, error) {
+	if scalar, ok := scalarFor(t); ok {
+		v, err := scalar.decode(value, layout)
+		if err != nil {
+			return reflect.Zero(t), err
+		}
+		if t.Kind() == reflect.Array {
+			// UUIDs are decoded as [16]byte
+			return reflect.ValueOf(v).Convert(t), nil
+		}
+		return reflect.ValueOf(v), nil
+	}
+	v, err := decodePrimitiveString(value, t.Kind())
+	if err != nil || !v.IsValid() {
+		return reflect.Zero(t), err
+	}
+	return v.Convert(t), nil
+}
+
+// encodeParamString is the inverse of decodeParamString, addr is a pointer to the value
+func encodeParamString(addr reflect.Value, layout string) string {
+	if scalar, ok := scalarFor(addr.Elem().Type()); ok {
+		return scalar.encode(addr.Elem(), layout)
+	}
+	return marshalPrimitiveToString(addr)
+}
+
+// newParamReflector returns a copy of reflector that describes scalars as the strings they are read from
+func newParamReflector(layout string, reflector jsonschema.Reflector) *jsonschema.Reflector {
+	reflector.Mapper = func(t reflect.Type) *jsonschema.Schema {
+		if scalar, ok := scalarFor(t); ok {
+			return scalar.schema(layout)
+		}
+		return nil
+	}
+	return newReflector(reflector)
+}
+
+// timeLayout gets the layout to use for time.Time values (defaults to RFC 3339)
+func timeLayout(layout string) string {
+	if layout == "" {
+		return time.RFC3339Nano
+	}
+	return layout
+}
+
+// parseDuration parses a Go (i.e. 1h30m) or ISO 8601 (i.e. PT1H30M) duration, ISO 8601 years and months are not supported
+func parseDuration(value string) (time.Duration, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return d, nil
+	}
+	invalid := errors.New("invalid duration: " + value)
+	raw := value
+	negative := strings.HasPrefix(raw, "-")
+	raw = strings.TrimPrefix(raw, "-")
+	raw, ok := strings.CutPrefix(raw, "P")
+	if !ok || raw == "" {
+		return 0, invalid
+	}
+	var d time.Duration
+	inTime := false
+	units := map[bool]map[byte]time.Duration{
+		false: {'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour},
+		true:  {'H': time.Hour, 'M': time.Minute, 'S': time.Second},
+	}
+	for raw != "" {
+		if raw[0] == 'T' && !inTime {
+			inTime = true
+			raw = raw[1:]
+			continue
+		}
+		end := strings.IndexFunc(raw, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.'
+		})
+		if end <= 0 {
+			return 0, invalid
+		}
+		unit, ok := units[inTime][raw[end]]
+		if !ok {
+			return 0, invalid
+		}
+		n, err := strconv.ParseFloat(raw[:end], 64)
+		if err != nil {
+			return 0, invalid
+		}
+		d += time.Duration(n * float64(unit))
+		raw = raw[end+1:]
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
+
+// formatDuration formats a duration using ISO 8601 (i.e. PT1H30M)
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	value := "PT"
+	if d < 0 {
+		value = "-PT"
+		d = -d
+	}
+	if h := d / time.Hour; h > 0 {
+		value += strconv.FormatInt(int64(h), 10) + "H"
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		value += strconv.FormatInt(int64(m), 10) + "M"
+		d -= m * time.Minute
+	}
+	if d > 0 {
+		value += strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S"
+	}
+	return value
+}
+
+// parseUUID parses a UUID with or without dashes (and optionally wrapped in braces or prefixed with urn:uuid:)
+func parseUUID(value string) ([16]byte, error) {
+	var uuid [16]byte
+	raw := strings.TrimPrefix(strings.ToLower(value), "urn:uuid:")
+	raw = strings.TrimSuffix(strings.TrimPrefix(raw, "{"), "}")
+	if len(raw) == 36 {
+		if raw[8] != '-' || raw[13] != '-' || raw[18] != '-' || raw[23] != '-' {
+			return uuid, errors.New("invalid UUID: " + value)
+		}
+		raw = strings.ReplaceAll(raw, "-", "")
+	}
+	if len(raw) != 32 {
+		return uuid, errors.New("invalid UUID: " + value)
+	}
+	if _, err := hex.Decode(uuid[:], []byte(raw)); err != nil {
+		return uuid, errors.New("invalid UUID: " + value)
+	}
+	return uuid, nil
+}
+
+// formatUUID formats a UUID as xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func formatUUID(uuid [16]byte) string {
+	raw := hex.EncodeToString(uuid[:])
+	return raw[:8] + "-" + raw[8:12] + "-" + raw[12:16] + "-" + raw[16:20] + "-" + raw[20:]
+}
@@ -0,0 +1,212 @@
+package chimera_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/invopop/jsonschema"
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestUUID [16]byte
+
+type TestScalarParams struct {
+	ID       TestUUID      `param:"id,in=path"`
+	At       time.Time     `param:"at,in=query"`
+	Day      time.Time     `param:"day,in=query,layout=2006-01-02"`
+	Timeout  time.Duration `param:"timeout,in=query"`
+	Backoff  time.Duration `param:"X-Backoff,in=header"`
+	Client   net.IP        `param:"client,in=query"`
+	Server   netip.Addr    `param:"X-Server,in=header"`
+	Callback *url.URL      `param:"callback,in=query"`
+	Related  []TestUUID    `param:"related,in=query"`
+}
+
+func TestScalarParamTypes(t *testing.T) {
+	expected := TestScalarParams{
+		ID:       TestUUID{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00},
+		At:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Day:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Timeout:  90 * time.Minute,
+		Backoff:  1500 * time.Millisecond,
+		Client:   net.ParseIP("10.0.0.1"),
+		Server:   netip.MustParseAddr("::1"),
+		Callback: &url.URL{Scheme: "https", Host: "example.com", Path: "/hook"},
+		Related:  []TestUUID{{1}},
+	}
+	api := chimera.NewAPI()
+	route := chimera.Get(api, "/items/{id}", func(req *chimera.NoBodyRequest[TestScalarParams]) (*chimera.EmptyResponse, error) {
+		assert.Equal(t, expected, req.Params)
+		return nil, nil
+	})
+	query := url.Values{
+		"at":       {"2024-01-02T03:04:05Z"},
+		"day":      {"2024-01-02"},
+		"timeout":  {"PT1H30M"},
+		"client":   {"10.0.0.1"},
+		"callback": {"https://example.com/hook"},
+		"related":  {"01000000-0000-0000-0000-000000000000"},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/items/123e4567-e89b-12d3-a456-426614174000?"+query.Encode(), nil)
+	req.Header.Set("X-Backoff", "1.5s")
+	req.Header.Set("X-Server", "::1")
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// invalid values are improperly formatted params
+	req = httptest.NewRequest(http.MethodGet, "/items/not-a-uuid?at=yesterday&client=localhost", nil)
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"id"`)
+	assert.Contains(t, w.Body.String(), `"name":"at"`)
+	assert.Contains(t, w.Body.String(), `"name":"client"`)
+
+	// requests are written the same way they are read
+	written := httptest.NewRequest(http.MethodGet, "/items/{id}", nil)
+	assert.NoError(t, chimera.MarshalRequestParams(written, &expected))
+	assert.Equal(t, "/items/123e4567-e89b-12d3-a456-426614174000", written.URL.Path)
+	assert.Equal(t, "2024-01-02T03:04:05Z", written.URL.Query().Get("at"))
+	assert.Equal(t, "2024-01-02", written.URL.Query().Get("day"))
+	assert.Equal(t, "PT1H30M", written.URL.Query().Get("timeout"))
+	assert.Equal(t, "https://example.com/hook", written.URL.Query().Get("callback"))
+	assert.Equal(t, "PT1.5S", written.Header.Get("X-Backoff"))
+	assert.Equal(t, "::1", written.Header.Get("X-Server"))
+
+	formats := map[string]string{}
+	for _, param := range route.OpenAPIOperationSpec().Parameters {
+		if param.Name == "client" {
+			assert.Equal(t, "ipv4", param.Schema.AnyOf[0].Format)
+			assert.Equal(t, "ipv6", param.Schema.AnyOf[1].Format)
+		}
+		if param.Name == "related" {
+			assert.Equal(t, "uuid", param.Schema.Items.Format)
+			continue
+		}
+		assert.Equal(t, "string", string(param.Schema.Type), param.Name)
+		formats[param.Name] = param.Schema.Format
+	}
+	assert.Equal(t, map[string]string{
+		"id":        "uuid",
+		"at":        "date-time",
+		"day":       "date",
+		"timeout":   "duration",
+		"X-Backoff": "duration",
+		"client":    "",
+		"X-Server":  "",
+		"callback":  "uri",
+	}, formats)
+}
+
+type TestStatus int
+
+const (
+	TestStatusOpen TestStatus = iota + 1
+	TestStatusClosed
+)
+
+func (s *TestStatus) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "open":
+		*s = TestStatusOpen
+	case "closed":
+		*s = TestStatusClosed
+	default:
+		return errors.New("invalid status")
+	}
+	return nil
+}
+
+func (s TestStatus) String() string {
+	return [...]string{"", "open", "closed"}[s]
+}
+
+func (TestStatus) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{Type: "string", Enum: []any{"open", "closed"}}
+}
+
+type TestAccountID struct {
+	value string
+}
+
+func (a *TestAccountID) UnmarshalText(text []byte) error {
+	value, ok := strings.CutPrefix(string(text), "acct_")
+	if !ok {
+		return errors.New("invalid account id")
+	}
+	a.value = value
+	return nil
+}
+
+func (a TestAccountID) MarshalText() ([]byte, error) {
+	return []byte("acct_" + a.value), nil
+}
+
+type TestTextParams struct {
+	Account  TestAccountID   `param:"account,in=path"`
+	Status   TestStatus      `param:"status,in=query"`
+	Statuses []TestStatus    `param:"statuses,in=query,explode"`
+	Owner    *TestAccountID  `param:"X-Owner,in=header"`
+	Others   []TestAccountID `param:"X-Others,in=header"`
+	Session  TestAccountID   `param:"session,in=cookie"`
+}
+
+func TestTextParamTypes(t *testing.T) {
+	expected := TestTextParams{
+		Account:  TestAccountID{"1"},
+		Status:   TestStatusOpen,
+		Statuses: []TestStatus{TestStatusOpen, TestStatusClosed},
+		Owner:    &TestAccountID{"2"},
+		Others:   []TestAccountID{{"3"}, {"4"}},
+		Session:  TestAccountID{"5"},
+	}
+	api := chimera.NewAPI()
+	route := chimera.Get(api, "/accounts/{account}", func(req *chimera.NoBodyRequest[TestTextParams]) (*chimera.NoBodyResponse[TestTextParams], error) {
+		assert.Equal(t, expected, req.Params)
+		return &chimera.NoBodyResponse[TestTextParams]{Params: req.Params}, nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/accounts/acct_1?status=open&statuses=open&statuses=closed", nil)
+	req.Header.Set("X-Owner", "acct_2")
+	req.Header.Set("X-Others", "acct_3,acct_4")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "acct_5"})
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "acct_2", w.Header().Get("X-Owner"))
+	assert.Equal(t, "acct_3,acct_4", w.Header().Get("X-Others"))
+
+	// fmt.Stringer is used for types that dont implement encoding.TextMarshaler
+	written := httptest.NewRequest(http.MethodGet, "/accounts/{account}", nil)
+	assert.NoError(t, chimera.MarshalRequestParams(written, &expected))
+	assert.Equal(t, "/accounts/acct_1", written.URL.Path)
+	assert.Equal(t, "open", written.URL.Query().Get("status"))
+	assert.Equal(t, []string{"open", "closed"}, written.URL.Query()["statuses"])
+
+	// invalid values are improperly formatted params
+	req = httptest.NewRequest(http.MethodGet, "/accounts/1?status=pending", nil)
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"account"`)
+	assert.Contains(t, w.Body.String(), `"name":"status"`)
+
+	for _, param := range route.OpenAPIOperationSpec().Parameters {
+		switch param.Name {
+		case "account", "X-Owner", "session":
+			assert.Equal(t, "string", string(param.Schema.Type), param.Name)
+		case "status":
+			assert.Equal(t, []any{"open", "closed"}, param.Schema.Enum)
+		case "X-Others":
+			assert.Equal(t, "string", string(param.Schema.Items.Type))
+		}
+	}
+}
@@ -0,0 +1,448 @@
+package chimera
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/invopop/jsonschema"
+)
+
+// SchemaNamer returns the component name to use for the schema of a named type, names are sanitized
+// and made unique by the schema registry so they only need to be readable
+type SchemaNamer func(t reflect.Type) string
+
+// schemaRegistry assigns a unique component name to every type that is reflected into a schema
+type schemaRegistry struct {
+	lock  sync.Mutex
+	namer SchemaNamer
+	names map[reflect.Type]string
+	// types has a nil type for names that are shared by several types (which no type gets)
+	types map[string]reflect.Type
+	// renames are the names given up by types (once another type wanted the same name) mapped to their new name
+	renames map[string]string
+}
+
+var (
+	registry = schemaRegistry{
+		namer:   DefaultSchemaNamer,
+		names:   make(map[reflect.Type]string),
+		types:   make(map[string]reflect.Type),
+		renames: make(map[string]string),
+	}
+)
+
+// SetSchemaNamer changes how component names are chosen for types, it should be called
+// before any routes are registered since names that were already assigned do not change
+func SetSchemaNamer(namer SchemaNamer) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	if namer == nil {
+		namer = DefaultSchemaNamer
+	}
+	registry.namer = namer
+}
+
+// SchemaName returns the name of the component schema used for a type (i.e. #/components/schemas/<name>),
+// types with the same name are all qualified by their package so the name changes once another type with
+// the same name is registered (the components of an API are renamed when it is rebuilt)
+func SchemaName(t reflect.Type) string {
+	return registry.name(t)
+}
+
+// DefaultSchemaNamer uses the name of the type with the type arguments of generic types
+// appended to it (i.e. Page[github.com/org/pkg.User] becomes Page_User)
+func DefaultSchemaNamer(t reflect.Type) string {
+	name, args, ok := strings.Cut(t.Name(), "[")
+	if !ok {
+		return name
+	}
+	args = strings.TrimSuffix(args, "]")
+	for _, arg := range splitTypeArgs(args) {
+		name += "_" + shortTypeName(arg)
+	}
+	return name
+}
+
+// splitTypeArgs splits the type arguments of a generic type name on the commas that arent nested
+func splitTypeArgs(args string) []string {
+	parts := make([]string, 0)
+	depth := 0
+	start := 0
+	for i, c := range args {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, args[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, args[start:])
+}
+
+// shortTypeName removes package paths from a type name (keeping nested type arguments)
+func shortTypeName(name string) string {
+	prefix := strings.TrimLeft(name, "[]*")
+	modifiers := name[:len(name)-len(prefix)]
+	base, args, generic := strings.Cut(prefix, "[")
+	if i := strings.LastIndex(base, "."); i >= 0 {
+		base = base[i+1:]
+	}
+	if generic {
+		for _, arg := range splitTypeArgs(strings.TrimSuffix(args, "]")) {
+			base += "_" + shortTypeName(arg)
+		}
+	}
+	if strings.Contains(modifiers, "[]") {
+		base += "List"
+	}
+	return base
+}
+
+// sanitizeSchemaName replaces all characters that arent allowed in component names (^[a-zA-Z0-9\.\-_]+$)
+func sanitizeSchemaName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// name returns the unique component name of a type, types that have the same name are all qualified
+// by their package (and then a number if that isnt enough) no matter which one was registered first
+func (r *schemaRegistry) name(t reflect.Type) string {
+	if t.Name() == "" {
+		return ""
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+	r.assign(t, 0)
+	return r.names[t]
+}
+
+// candidates returns the names a type can have (in order of preference)
+func (r *schemaRegistry) candidates(t reflect.Type) []string {
+	base := sanitizeSchemaName(r.namer(t))
+	if base == "" {
+		base = sanitizeSchemaName(DefaultSchemaNamer(t))
+	}
+	pkg := t.PkgPath()
+	candidates := []string{base}
+	for _, qualified := range []string{
+		sanitizeSchemaName(pkg[strings.LastIndex(pkg, "/")+1:]) + "_" + base,
+		sanitizeSchemaName(pkg) + "_" + base,
+	} {
+		if qualified != candidates[len(candidates)-1] {
+			candidates = append(candidates, qualified)
+		}
+	}
+	return candidates
+}
+
+// assign gives a type the first of its candidates (starting at level) that no other type wants, if another type
+// already has the candidate it is renamed to its next candidate so neither type gets it
+func (r *schemaRegistry) assign(t reflect.Type, level int) {
+	candidates := r.candidates(t)
+	for ; level < len(candidates); level++ {
+		other, taken := r.types[candidates[level]]
+		if !taken {
+			r.names[t] = candidates[level]
+			r.types[candidates[level]] = t
+			return
+		}
+		if other != nil {
+			r.types[candidates[level]] = nil
+			delete(r.names, other)
+			otherLevel := 0
+			for i, candidate := range r.candidates(other) {
+				if candidate == candidates[level] {
+					otherLevel = i + 1
+				}
+			}
+			r.assign(other, otherLevel)
+			r.renames[candidates[level]] = r.names[other]
+		}
+	}
+	for i := 2; ; i++ {
+		candidate := candidates[0] + "_" + strconv.Itoa(i)
+		if _, taken := r.types[candidate]; !taken {
+			r.names[t] = candidate
+			r.types[candidate] = t
+			return
+		}
+	}
+}
+
+// renameSchemas renames the component schemas of a spec (and every $ref to them) whose type was renamed
+// after they were added
+func (r *schemaRegistry) renameSchemas(spec *OpenAPI) {
+	if spec.Components == nil || len(spec.Components.Schemas) == 0 {
+		return
+	}
+	r.lock.Lock()
+	renames := make(map[string]string)
+	for name := range spec.Components.Schemas {
+		renamed, ok := r.renames[name]
+		for ok {
+			renames[name] = renamed
+			renamed, ok = r.renames[renamed]
+		}
+	}
+	r.lock.Unlock()
+	if len(renames) == 0 {
+		return
+	}
+	for name, renamed := range renames {
+		def := spec.Components.Schemas[name]
+		delete(spec.Components.Schemas, name)
+		// the renamed type may have been added again since (i.e. by a newer route)
+		final := renamed
+		for i := 2; ; i++ {
+			existing, ok := spec.Components.Schemas[final]
+			if !ok || schemasEqual(&existing, &def) {
+				break
+			}
+			final = renamed + "_" + strconv.Itoa(i)
+		}
+		renames[name] = final
+		spec.Components.Schemas[final] = def
+	}
+	for name, def := range spec.Components.Schemas {
+		rewriteRefs(&def, renames)
+		spec.Components.Schemas[name] = def
+	}
+	forEachSpecSchema(spec, func(s *jsonschema.Schema) {
+		rewriteRefs(s, renames)
+	})
+}
+
+// newReflector returns a copy of reflector that names definitions using the schema registry
+func newReflector(reflector jsonschema.Reflector) *jsonschema.Reflector {
+	base := reflector
+	reflector.Namer = registry.name
+	reflector.Mapper = func(t reflect.Type) *jsonschema.Schema {
+		if schema := optionalSchema(base, t); schema != nil {
+			return schema
+		}
+		if schema := oneOfBodySchema(base, t); schema != nil {
+			return schema
+		}
+		if base.Mapper != nil {
+			return base.Mapper(t)
+		}
+		return nil
+	}
+	return &reflector
+}
+
+// schemasEqual checks if 2 schemas would be serialized the same way
+func schemasEqual(a, b *jsonschema.Schema) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	return errX == nil && errY == nil && string(x) == string(y)
+}
+
+// standardizedSchemas moves all of the $defs of a schema into defs (i.e. components.schemas) and rewrites
+// every $ref to match. Definitions are named by the schema registry so the same type always ends up as the same
+// component, if a type was reflected differently (i.e. using a different field tag) it is added under a new name.
+func standardizedSchemas(schema *jsonschema.Schema, defs map[string]jsonschema.Schema) {
+	if schema == nil {
+		return
+	}
+	found := make(map[string]*jsonschema.Schema)
+	collectDefinitions(schema, found)
+	dropOptionalRequired(schema)
+	for _, def := range found {
+		dropOptionalRequired(def)
+	}
+	renames := make(map[string]string)
+	// refs between definitions have to point at components before they can be compared
+	for _, def := range found {
+		rewriteRefs(def, renames)
+	}
+	for name, def := range found {
+		final := name
+		for i := 2; ; i++ {
+			existing, ok := defs[final]
+			if !ok || schemasEqual(&existing, def) {
+				break
+			}
+			final = name + "_" + strconv.Itoa(i)
+		}
+		renames[name] = final
+	}
+	for _, def := range found {
+		rewriteRefs(def, renames)
+	}
+	rewriteRefs(schema, renames)
+	for name, def := range found {
+		if _, ok := defs[renames[name]]; !ok {
+			defs[renames[name]] = *def
+		}
+	}
+}
+
+// forEachSpecSchema runs f on every schema of a spec (excluding component schemas and sub-schemas)
+func forEachSpecSchema(spec *OpenAPI, f func(*jsonschema.Schema)) {
+	var eachPath func(Path)
+	eachParameter := func(params ...Parameter) {
+		for _, param := range params {
+			if param.Schema != nil {
+				f(param.Schema)
+			}
+		}
+	}
+	eachContent := func(content map[string]MediaType) {
+		for _, media := range content {
+			if media.Schema != nil {
+				f(media.Schema)
+			}
+			for _, encoding := range media.Encoding {
+				for _, header := range encoding.Headers {
+					eachParameter(header)
+				}
+			}
+		}
+	}
+	eachResponse := func(responses Responses) {
+		for _, response := range responses {
+			for _, header := range response.Headers {
+				eachParameter(header)
+			}
+			eachContent(response.Content)
+		}
+	}
+	eachCallback := func(callbacks map[string]map[string]Path) {
+		for _, callback := range callbacks {
+			for _, path := range callback {
+				eachPath(path)
+			}
+		}
+	}
+	eachPath = func(path Path) {
+		eachParameter(path.Parameters...)
+		for _, op := range []*Operation{path.Get, path.Put, path.Post, path.Delete, path.Options, path.Head, path.Patch, path.Trace} {
+			if op == nil {
+				continue
+			}
+			if op.RequestSpec != nil {
+				eachParameter(op.Parameters...)
+				if op.RequestBody != nil {
+					eachContent(op.RequestBody.Content)
+				}
+			}
+			eachResponse(op.Responses)
+			eachCallback(op.Callbacks)
+		}
+	}
+	for _, paths := range []map[string]Path{spec.Paths, spec.Webhooks} {
+		for _, path := range paths {
+			eachPath(path)
+		}
+	}
+	if spec.Components == nil {
+		return
+	}
+	eachResponse(spec.Components.Responses)
+	for _, param := range spec.Components.Parameters {
+		eachParameter(param)
+	}
+	for _, body := range spec.Components.RequestBodies {
+		eachContent(body.Content)
+	}
+	for _, headers := range spec.Components.Headers {
+		for _, header := range headers {
+			eachParameter(header)
+		}
+	}
+	eachCallback(spec.Components.Callbacks)
+	for _, path := range spec.Components.PathItems {
+		eachPath(path)
+	}
+}
+
+// forEachSubSchema runs f on every direct sub-schema of a schema (excluding definitions)
+func forEachSubSchema(schema *jsonschema.Schema, f func(*jsonschema.Schema)) {
+	for _, s := range []*jsonschema.Schema{
+		schema.AdditionalProperties, schema.Contains, schema.ContentSchema, schema.Else,
+		schema.If, schema.Items, schema.Not, schema.PropertyNames, schema.Then,
+	} {
+		if s != nil {
+			f(s)
+		}
+	}
+	for _, list := range [][]*jsonschema.Schema{schema.AllOf, schema.AnyOf, schema.OneOf, schema.PrefixItems} {
+		for _, s := range list {
+			if s != nil {
+				f(s)
+			}
+		}
+	}
+	for _, m := range []map[string]*jsonschema.Schema{schema.DependentSchemas, schema.PatternProperties} {
+		for _, s := range m {
+			if s != nil {
+				f(s)
+			}
+		}
+	}
+	if schema.Properties != nil {
+		for p := schema.Properties.Oldest(); p != nil; p = p.Next() {
+			if p.Value != nil {
+				f(p.Value)
+			}
+		}
+	}
+}
+
+// collectDefinitions removes the $defs (and ids) from a schema and all of its sub-schemas and adds them to found
+func collectDefinitions(schema *jsonschema.Schema, found map[string]*jsonschema.Schema) {
+	schema.ID = ""
+	schema.Version = ""
+	for name, def := range schema.Definitions {
+		found[name] = def
+		collectDefinitions(def, found)
+	}
+	schema.Definitions = nil
+	forEachSubSchema(schema, func(s *jsonschema.Schema) {
+		collectDefinitions(s, found)
+	})
+}
+
+// rewriteRefs points every $ref of a schema (and its sub-schemas) to components.schemas using renames
+func rewriteRefs(schema *jsonschema.Schema, renames map[string]string) {
+	for _, prefix := range []string{"#/$defs/", "#/components/schemas/"} {
+		if name, ok := strings.CutPrefix(schema.Ref, prefix); ok {
+			if renamed, ok := renames[name]; ok {
+				name = renamed
+			}
+			schema.Ref = "#/components/schemas/" + name
+			break
+		}
+	}
+	// the mapping of discriminators (i.e. from OneOfBody) refers to components too
+	if discriminator, ok := schema.Extras["discriminator"].(map[string]any); ok {
+		if mapping, ok := discriminator["mapping"].(map[string]string); ok {
+			for value, ref := range mapping {
+				if name, ok := strings.CutPrefix(ref, "#/components/schemas/"); ok {
+					if renamed, ok := renames[name]; ok {
+						mapping[value] = "#/components/schemas/" + renamed
+					}
+				}
+			}
+		}
+	}
+	forEachSubSchema(schema, func(s *jsonschema.Schema) {
+		rewriteRefs(s, renames)
+	})
+}
@@ -0,0 +1,117 @@
+package chimera_test
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type Userinfo struct {
+	Name string `json:"name"`
+}
+
+type TestSchemaItem struct {
+	ID int `json:"id"`
+}
+
+type TestSchemaPage[T any] struct {
+	Items []T `json:"items"`
+}
+
+type TestSchemaBody struct {
+	Mine   Userinfo                       `json:"mine"`
+	Theirs url.Userinfo                   `json:"theirs"`
+	Page   TestSchemaPage[TestSchemaItem] `json:"page"`
+}
+
+type TestSchemaNamed struct {
+	Value string `json:"value"`
+}
+
+type Values struct {
+	Key string `json:"key"`
+}
+
+type TestSchemaValues struct {
+	Values Values `json:"values"`
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	chimera.SetSchemaNamer(func(t reflect.Type) string {
+		if t == reflect.TypeOf(TestSchemaNamed{}) {
+			return "Custom Name!"
+		}
+		return ""
+	})
+	defer chimera.SetSchemaNamer(nil)
+
+	api := chimera.NewAPI()
+	chimera.Post(api, "/first", func(*chimera.JSON[TestSchemaBody, chimera.Nil]) (*chimera.JSON[TestSchemaNamed, chimera.Nil], error) {
+		return nil, nil
+	})
+	chimera.Post(api, "/second", func(*chimera.JSON[TestSchemaBody, chimera.Nil]) (*chimera.JSON[[]TestSchemaItem, chimera.Nil], error) {
+		return nil, nil
+	})
+
+	mine := chimera.SchemaName(reflect.TypeOf(Userinfo{}))
+	theirs := chimera.SchemaName(reflect.TypeOf(url.Userinfo{}))
+	// types with the same name are all qualified by their package
+	assert.Equal(t, "chimera_test_Userinfo", mine)
+	assert.Equal(t, "url_Userinfo", theirs)
+	assert.Equal(t, "TestSchemaPage_TestSchemaItem", chimera.SchemaName(reflect.TypeOf(TestSchemaPage[TestSchemaItem]{})))
+	assert.Equal(t, "Custom_Name_", chimera.SchemaName(reflect.TypeOf(TestSchemaNamed{})))
+	assert.Equal(t, "", chimera.SchemaName(reflect.TypeOf([]int{})))
+
+	schemas := api.OpenAPISpec().Components.Schemas
+	for _, name := range []string{mine, theirs, "TestSchemaPage_TestSchemaItem", "TestSchemaItem", "TestSchemaBody", "Custom_Name_"} {
+		assert.Contains(t, schemas, name)
+	}
+	// the same type is only added once
+	count := 0
+	for name := range schemas {
+		if strings.HasPrefix(name, "TestSchemaBody") {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+
+	body := schemas["TestSchemaBody"]
+	mineProp, _ := body.Properties.Get("mine")
+	theirsProp, _ := body.Properties.Get("theirs")
+	assert.Equal(t, "#/components/schemas/"+mine, mineProp.Ref)
+	assert.Equal(t, "#/components/schemas/"+theirs, theirsProp.Ref)
+	spec, err := chimera.MarshalSpec(api.OpenAPISpec(), chimera.SpecFormatJSON)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(spec), "$defs")
+}
+
+func TestSchemaRegistryRenames(t *testing.T) {
+	api := chimera.NewAPI()
+	chimera.Post(api, "/values", func(*chimera.JSON[TestSchemaValues, chimera.Nil]) (*chimera.JSON[TestSchemaValues, chimera.Nil], error) {
+		return nil, nil
+	})
+	assert.Equal(t, "Values", chimera.SchemaName(reflect.TypeOf(Values{})))
+	assert.Contains(t, api.OpenAPISpec().Components.Schemas, "Values")
+
+	// the type that had the name is renamed too so the order types are added in doesnt matter
+	assert.Equal(t, "url_Values", chimera.SchemaName(reflect.TypeOf(url.Values{})))
+	assert.Equal(t, "chimera_test_Values", chimera.SchemaName(reflect.TypeOf(Values{})))
+
+	// its components are renamed once the API is rebuilt
+	chimera.Get(api, "/ping", func(*chimera.EmptyRequest) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+	schemas := api.OpenAPISpec().Components.Schemas
+	assert.NotContains(t, schemas, "Values")
+	assert.Contains(t, schemas, "chimera_test_Values")
+	body := schemas["TestSchemaValues"]
+	values, _ := body.Properties.Get("values")
+	assert.Equal(t, "#/components/schemas/chimera_test_Values", values.Ref)
+	spec, err := chimera.MarshalSpec(api.OpenAPISpec(), chimera.SpecFormatJSON)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(spec), `"#/components/schemas/Values"`)
+}
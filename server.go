@@ -0,0 +1,190 @@
+package chimera
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const (
+	defaultShutdownTimeout = 30 * time.Second
+)
+
+// LifecycleFunc is a hook that runs when a server starts up or shuts down
+type LifecycleFunc func(ctx context.Context) error
+
+// ServeOptions controls how API.Serve listens for and serves requests
+type ServeOptions struct {
+	// Addr is the TCP address to listen on (i.e. ":8000")
+	Addr string
+	// UnixSocket is the path of a unix socket to listen on instead of Addr
+	UnixSocket string
+	// Listener is a custom listener to serve from, it takes precedence over Addr and UnixSocket
+	Listener net.Listener
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	// CertFile and KeyFile enable TLS using the provided files
+	CertFile string
+	KeyFile  string
+	// TLSConfig enables TLS using the provided config, if CertFile/KeyFile are empty
+	// the config must provide its own certificates
+	TLSConfig *tls.Config
+	// H2C enables HTTP/2 over cleartext connections (ignored when using TLS)
+	H2C bool
+
+	// ShutdownSignals are the signals that trigger a graceful shutdown (defaults to SIGINT and SIGTERM)
+	ShutdownSignals []os.Signal
+	// ShutdownTimeout is how long in-flight requests have to finish during shutdown (defaults to 30s)
+	ShutdownTimeout time.Duration
+}
+
+// OnStartup adds hooks that are run (in order) by Serve before any requests are accepted
+func (a *API) OnStartup(hooks ...LifecycleFunc) {
+	a.startupHooks = append(a.startupHooks, hooks...)
+}
+
+// OnShutdown adds hooks that are run (in order) by Serve after in-flight requests have drained
+func (a *API) OnShutdown(hooks ...LifecycleFunc) {
+	a.shutdownHooks = append(a.shutdownHooks, hooks...)
+}
+
+// lifecycleAPIs gets an API and its sub-APIs (parents first) which is the order their hooks run in
+func (a *API) lifecycleAPIs() []*API {
+	apis := []*API{a}
+	for _, sub := range a.subAPIs {
+		apis = append(apis, sub.lifecycleAPIs()...)
+	}
+	return apis
+}
+
+// shutdownTimeout is how long requests and hooks get to finish during shutdown
+func (opts *ServeOptions) shutdownTimeout() time.Duration {
+	if opts.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return opts.ShutdownTimeout
+}
+
+// rollback runs the shutdown hooks of APIs that started (in reverse order) after a startup hook failed,
+// their errors are dropped in favor of the startup error
+func (opts *ServeOptions) rollback(started []*API) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.shutdownTimeout())
+	defer cancel()
+	for i := len(started) - 1; i >= 0; i-- {
+		for j := len(started[i].shutdownHooks) - 1; j >= 0; j-- {
+			started[i].shutdownHooks[j](ctx)
+		}
+	}
+}
+
+// listen creates the listener described by opts
+func (opts *ServeOptions) listen() (net.Listener, error) {
+	if opts.Listener != nil {
+		return opts.Listener, nil
+	}
+	if opts.UnixSocket != "" {
+		// clean up stale sockets from previous runs, but never anything else
+		if info, err := os.Stat(opts.UnixSocket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(opts.UnixSocket)
+		}
+		return net.Listen("unix", opts.UnixSocket)
+	}
+	addr := opts.Addr
+	if addr == "" {
+		addr = ":http"
+		if opts.TLSConfig != nil || opts.CertFile != "" {
+			addr = ":https"
+		}
+	}
+	return net.Listen("tcp", addr)
+}
+
+// Serve serves requests until ctx is done or one of the shutdown signals is received at which point
+// the server stops accepting connections and waits up to ShutdownTimeout for in-flight requests to finish.
+// OnStartup hooks are run before serving and OnShutdown hooks are run after draining.
+// If an OnStartup hook fails, the OnShutdown hooks of the APIs whose startup hooks all succeeded
+// are run in reverse order before its error is returned.
+// Serve returns nil on a graceful shutdown.
+func (a *API) Serve(ctx context.Context, opts ServeOptions) error {
+	apis := a.lifecycleAPIs()
+	listener, err := opts.listen()
+	if err != nil {
+		return err
+	}
+	for i, api := range apis {
+		for _, hook := range api.startupHooks {
+			if err := hook(ctx); err != nil {
+				listener.Close()
+				opts.rollback(apis[:i])
+				return err
+			}
+		}
+	}
+
+	useTLS := opts.TLSConfig != nil || opts.CertFile != ""
+	var handler http.Handler = a
+	if opts.H2C && !useTLS {
+		handler = h2c.NewHandler(a, &http2.Server{IdleTimeout: opts.IdleTimeout})
+	}
+	server := &http.Server{
+		Handler:           handler,
+		TLSConfig:         opts.TLSConfig,
+		ReadTimeout:       opts.ReadTimeout,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+		MaxHeaderBytes:    opts.MaxHeaderBytes,
+	}
+
+	signals := opts.ShutdownSignals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	sigCtx, stop := signal.NotifyContext(ctx, signals...)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if useTLS {
+			serveErr <- server.ServeTLS(listener, opts.CertFile, opts.KeyFile)
+		} else {
+			serveErr <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err = <-serveErr:
+	case <-sigCtx.Done():
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.shutdownTimeout())
+	defer cancel()
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
+	for _, api := range apis {
+		for _, hook := range api.shutdownHooks {
+			if hookErr := hook(shutdownCtx); hookErr != nil && err == nil {
+				err = hookErr
+			}
+		}
+	}
+	return err
+}
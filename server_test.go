@@ -0,0 +1,106 @@
+package chimera_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServe(t *testing.T) {
+	api := chimera.NewAPI()
+	chimera.Get(api, "/route", func(*chimera.EmptyRequest) (*chimera.Response, error) {
+		return &chimera.Response{
+			Body: []byte("served"),
+		}, nil
+	})
+	called := make([]string, 0)
+	api.OnStartup(func(ctx context.Context) error {
+		called = append(called, "startup")
+		return nil
+	})
+	api.Group("/group").OnShutdown(func(ctx context.Context) error {
+		called = append(called, "shutdown")
+		return nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- api.Serve(ctx, chimera.ServeOptions{
+			Listener:        listener,
+			ReadTimeout:     time.Second,
+			ShutdownTimeout: time.Second,
+		})
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/route")
+	assert.NoError(t, err)
+	assert.Equal(t, resp.StatusCode, 200)
+	b, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, b, []byte("served"))
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Equal(t, called, []string{"startup", "shutdown"})
+
+	socket := filepath.Join(t.TempDir(), "chimera.sock")
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		done <- api.Serve(ctx, chimera.ServeOptions{
+			UnixSocket: socket,
+			H2C:        true,
+		})
+	}()
+	client := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	assert.Eventually(t, func() bool {
+		resp, err = client.Get("http://unix/route")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, resp.StatusCode, 200)
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestServeStartupFailure(t *testing.T) {
+	api := chimera.NewAPI()
+	called := make([]string, 0)
+	hook := func(name string, err error) chimera.LifecycleFunc {
+		return func(ctx context.Context) error {
+			called = append(called, name)
+			return err
+		}
+	}
+	api.OnStartup(hook("startup", nil))
+	api.OnShutdown(hook("shutdown", nil))
+	group := api.Group("/group")
+	group.OnStartup(hook("group startup", nil))
+	group.OnShutdown(hook("group shutdown", nil))
+	failed := api.Group("/failed")
+	failed.OnStartup(hook("failed startup", errors.New("failed")))
+	failed.OnShutdown(hook("failed shutdown", nil))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	err = api.Serve(context.Background(), chimera.ServeOptions{Listener: listener})
+	assert.EqualError(t, err, "failed")
+	// only what started is shut down, in reverse order
+	assert.Equal(t, []string{"startup", "group startup", "failed startup", "group shutdown", "shutdown"}, called)
+	_, err = net.Dial("tcp", listener.Addr().String())
+	assert.Error(t, err)
+}
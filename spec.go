@@ -0,0 +1,107 @@
+package chimera
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SpecFormat is a format that the OpenAPI spec can be written in
+type SpecFormat string
+
+const (
+	SpecFormatJSON SpecFormat = "json"
+	SpecFormatYAML SpecFormat = "yaml"
+)
+
+// MarshalSpec marshals an OpenAPI spec in the provided format. The output is byte-stable for the same spec:
+// map keys are sorted and everything else keeps the order it was defined in, so it can be committed and diffed.
+// JSON output is indented and both formats end in a newline.
+func MarshalSpec(spec *OpenAPI, format SpecFormat) ([]byte, error) {
+	doc, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case SpecFormatJSON:
+		indented := bytes.Buffer{}
+		if err := json.Indent(&indented, doc, "", "  "); err != nil {
+			return nil, err
+		}
+		indented.WriteByte('\n')
+		return indented.Bytes(), nil
+	case SpecFormatYAML:
+		return jsonToYAML(doc)
+	}
+	return nil, fmt.Errorf("chimera.MarshalSpec: unsupported format %q", format)
+}
+
+// jsonToYAML converts a JSON document to YAML keeping the order of its keys
+// (json is valid yaml so the node tree can just be restyled)
+func jsonToYAML(doc []byte) ([]byte, error) {
+	node := yaml.Node{}
+	if err := yaml.Unmarshal(doc, &node); err != nil {
+		return nil, err
+	}
+	var restyle func(*yaml.Node)
+	restyle = func(n *yaml.Node) {
+		n.Style = 0
+		for _, child := range n.Content {
+			restyle(child)
+		}
+	}
+	restyle(&node)
+	out := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// spec returns the full spec of the API tree that a belongs to (the same one served at /openapi.json)
+func (a *API) spec() *OpenAPI {
+	root := a
+	for ; root.parent != nil; root = root.parent {
+	}
+	if root.servedSpec != nil {
+		return root.withOperationDefaults(root.servedSpec)
+	}
+	return &root.openAPISpec
+}
+
+// WriteSpec writes the OpenAPI spec of the API to w without serving anything
+// (i.e. to generate the spec in CI)
+func (a *API) WriteSpec(w io.Writer, format SpecFormat) error {
+	doc, err := MarshalSpec(a.spec(), format)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(doc)
+	return err
+}
+
+// WriteSpecFile writes the OpenAPI spec of the API to a file using its extension
+// to determine the format (.yaml/.yml for YAML and JSON otherwise)
+func (a *API) WriteSpecFile(path string) error {
+	format := SpecFormatJSON
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = SpecFormatYAML
+	}
+	doc, err := MarshalSpec(a.spec(), format)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, doc, 0o644)
+}
@@ -0,0 +1,100 @@
+package chimera_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteSpec(t *testing.T) {
+	api := chimera.NewAPI()
+	sub := api.Group("/sub")
+	chimera.Get(sub, "/items/{id}", func(*chimera.NoBodyRequest[TestClientParams]) (*chimera.JSON[TestClientBody, chimera.Nil], error) {
+		return nil, nil
+	})
+	chimera.Post(api, "/items", func(*chimera.JSON[TestClientBody, chimera.Nil]) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+
+	jsonSpec := bytes.Buffer{}
+	assert.NoError(t, api.WriteSpec(&jsonSpec, chimera.SpecFormatJSON))
+	yamlSpec := bytes.Buffer{}
+	assert.NoError(t, sub.WriteSpec(&yamlSpec, chimera.SpecFormatYAML))
+	assert.Error(t, api.WriteSpec(io.Discard, "toml"))
+
+	// output should be stable
+	for i := 0; i < 5; i++ {
+		again := bytes.Buffer{}
+		assert.NoError(t, api.WriteSpec(&again, chimera.SpecFormatYAML))
+		assert.Equal(t, yamlSpec.String(), again.String())
+	}
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/openapi.json")
+	assert.NoError(t, err)
+	served, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, string(served), jsonSpec.String())
+
+	resp, err = http.Get(server.URL + "/openapi.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "application/yaml", resp.Header.Get("Content-Type"))
+	servedYAML, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, yamlSpec.String(), string(servedYAML))
+
+	var fromJSON, fromYAML any
+	assert.NoError(t, json.Unmarshal(jsonSpec.Bytes(), &fromJSON))
+	assert.NoError(t, yaml.Unmarshal(yamlSpec.Bytes(), &fromYAML))
+	assert.Equal(t, fromJSON, fromYAML)
+	assert.Contains(t, fromJSON.(map[string]any)["paths"], "/sub/items/{id}")
+
+	dir := t.TempDir()
+	assert.NoError(t, api.WriteSpecFile(filepath.Join(dir, "openapi.yml")))
+	file, err := os.ReadFile(filepath.Join(dir, "openapi.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, yamlSpec.Bytes(), file)
+	assert.NoError(t, api.WriteSpecFile(filepath.Join(dir, "openapi.json")))
+	file, err = os.ReadFile(filepath.Join(dir, "openapi.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, jsonSpec.Bytes(), file)
+}
+
+func TestServedSpecConcurrency(t *testing.T) {
+	api := chimera.NewAPI(chimera.WithDocs(chimera.DocsOptions{Spec30Path: "/openapi-3.0.json"}))
+	chimera.Post(api, "/items", func(*chimera.JSON[TestClientBody, chimera.Nil]) (*chimera.EmptyResponse, error) {
+		return nil, nil
+	})
+	// the specs are marshaled lazily so the first requests can race each other
+	paths := []string{"/openapi.json", "/openapi.yaml", "/openapi-3.0.json"}
+	bodies := make([][]string, len(paths))
+	wg := sync.WaitGroup{}
+	for i, path := range paths {
+		bodies[i] = make([]string, 10)
+		for j := range bodies[i] {
+			wg.Add(1)
+			go func(i, j int, path string) {
+				defer wg.Done()
+				w := httptest.NewRecorder()
+				api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+				bodies[i][j] = w.Body.String()
+			}(i, j, path)
+		}
+	}
+	wg.Wait()
+	for i := range paths {
+		assert.NotEmpty(t, bodies[i][0], paths[i])
+		for _, body := range bodies[i] {
+			assert.Equal(t, bodies[i][0], body, paths[i])
+		}
+	}
+}
@@ -14,6 +14,10 @@ var (
 	_ ResponseWriter = new(PlainTextResponse[Nil])
 	_ RequestReader  = new(PlainText[Nil])
 	_ ResponseWriter = new(PlainText[Nil])
+	_ RequestWriter  = new(PlainTextRequest[Nil])
+	_ ResponseReader = new(PlainTextResponse[Nil])
+	_ RequestWriter  = new(PlainText[Nil])
+	_ ResponseReader = new(PlainText[Nil])
 )
 
 // PlainTextRequest is any text/plain request that results in a string body
@@ -31,6 +35,11 @@ func (r *PlainTextRequest[Params]) Context() context.Context {
 	return nil
 }
 
+// httpRequest returns the original http.Request (see Callback.URL)
+func (r *PlainTextRequest[Params]) httpRequest() *http.Request {
+	return r.request
+}
+
 func readPlainTextRequest[Params any](req *http.Request, body *string, params *Params) error {
 	defer req.Body.Close()
 	b, err := io.ReadAll(req.Body)
@@ -56,6 +65,33 @@ func (r *PlainTextRequest[Params]) ReadRequest(req *http.Request) error {
 	return readPlainTextRequest(req, &r.Body, &r.Params)
 }
 
+func writePlainTextRequest[Params any](req *http.Request, body *string, params *Params) error {
+	setRequestBody(req, "text/plain", []byte(*body))
+
+	if _, ok := any(params).(*Nil); !ok {
+		return MarshalRequestParams(req, params)
+	}
+	return nil
+}
+
+// WriteRequest writes the Body field to the request and the Params field using MarshalRequestParams
+func (r *PlainTextRequest[Params]) WriteRequest(req *http.Request) error {
+	return writePlainTextRequest(req, &r.Body, &r.Params)
+}
+
+func readPlainTextResponse[Params any](resp *http.Response, body *string, params *Params) error {
+	b, err := readResponseBody(resp)
+	if err != nil {
+		return err
+	}
+	*body = string(b)
+
+	if _, ok := any(params).(*Nil); !ok {
+		return UnmarshalResponseParams(resp, params)
+	}
+	return nil
+}
+
 func textRequestSpec[Params any](schema *RequestSpec) {
 	schema.RequestBody = &RequestBody{
 		Content: map[string]MediaType{
@@ -136,16 +172,13 @@ func (r *PlainTextResponse[Params]) OpenAPIResponsesSpec() Responses {
 // WriteHead write the header for this response object
 func (r *PlainTextResponse[Params]) WriteHead(head *ResponseHead) error {
 	head.Headers.Set("Content-Type", "text/plain")
-	h, err := MarshalParams(&r.Params)
-	if err != nil {
-		return err
-	}
-	for k, v := range h {
-		for _, x := range v {
-			head.Headers.Add(k, x)
-		}
-	}
-	return nil
+	return head.MarshalParams(&r.Params)
+}
+
+// ReadResponse reads the response body into the Body field
+// and the response headers into the Params field using UnmarshalResponseParams
+func (r *PlainTextResponse[Params]) ReadResponse(resp *http.Response) error {
+	return readPlainTextResponse(resp, &r.Body, &r.Params)
 }
 
 // NewPlainTextResponse creates a PlainTextResponse from a string and params
@@ -172,6 +205,11 @@ func (r *PlainText[Params]) Context() context.Context {
 	return nil
 }
 
+// httpRequest returns the original http.Request (see Callback.URL)
+func (r *PlainText[Params]) httpRequest() *http.Request {
+	return r.request
+}
+
 // ReadRequest reads the body of an http request and assigns it to the Body field using io.ReadAll.
 // This function also reads the parameters using UnmarshalParams and assigns it to the Params field.
 // NOTE: the body of the request is closed after this function is run.
@@ -180,6 +218,17 @@ func (r *PlainText[Params]) ReadRequest(req *http.Request) error {
 	return readPlainTextRequest(req, &r.Body, &r.Params)
 }
 
+// WriteRequest writes the Body field to the request and the Params field using MarshalRequestParams
+func (r *PlainText[Params]) WriteRequest(req *http.Request) error {
+	return writePlainTextRequest(req, &r.Body, &r.Params)
+}
+
+// ReadResponse reads the response body into the Body field
+// and the response headers into the Params field using UnmarshalResponseParams
+func (r *PlainText[Params]) ReadResponse(resp *http.Response) error {
+	return readPlainTextResponse(resp, &r.Body, &r.Params)
+}
+
 // OpenAPIRequestSpec describes the RequestSpec for text/plain requests
 func (r *PlainText[Params]) OpenAPIRequestSpec() RequestSpec {
 	schema := RequestSpec{}
@@ -203,14 +252,5 @@ func (r *PlainText[Params]) OpenAPIResponsesSpec() Responses {
 // WriteHead writes the header for this response object
 func (r *PlainText[Params]) WriteHead(head *ResponseHead) error {
 	head.Headers.Set("Content-Type", "text/plain")
-	h, err := MarshalParams(&r.Params)
-	if err != nil {
-		return err
-	}
-	for k, v := range h {
-		for _, x := range v {
-			head.Headers.Add(k, x)
-		}
-	}
-	return nil
+	return head.MarshalParams(&r.Params)
 }
@@ -0,0 +1,203 @@
+package chimera
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultWebhookAttempts       = 5
+	defaultWebhookInitialBackoff = 500 * time.Millisecond
+	defaultWebhookMaxBackoff     = 30 * time.Second
+)
+
+// WebhookRequestPtr is a pointer to a request type that can be documented and sent as a webhook payload
+type WebhookRequestPtr[T any] interface {
+	RequestWriter
+	OpenAPIRequestSpec() RequestSpec
+	*T
+}
+
+// WebhookResponsePtr is a pointer to a response type that can be documented and read from a webhook subscriber
+type WebhookResponsePtr[T any] interface {
+	ResponseReader
+	OpenAPIResponsesSpec() Responses
+	*T
+}
+
+// typedOperation builds the Operation of an outgoing request (i.e. a webhook or callback) where Req is sent
+// and Resp is received, schemas are added to the components of api
+func typedOperation[Req, Resp any, ReqPtr WebhookRequestPtr[Req], RespPtr WebhookResponsePtr[Resp]](api *API) *Operation {
+	reqSchema := ReqPtr(new(Req)).OpenAPIRequestSpec()
+	operation := Operation{
+		RequestSpec: &reqSchema,
+		Responses:   RespPtr(new(Resp)).OpenAPIResponsesSpec(),
+	}
+	api.standardizeOperationSchemas(&operation)
+	if err := validateOperationExamples(&operation, api.openAPISpec.Components.Schemas); err != nil {
+		panic("chimera: " + err.Error())
+	}
+	// the receiver is expected to respond with a 200 unless specified otherwise
+	if r, ok := operation.Responses[""]; ok {
+		operation.Responses["200"] = r
+		delete(operation.Responses, "")
+	} else if len(operation.Responses) == 0 {
+		operation.Responses = Responses{"200": {}}
+	}
+	return &operation
+}
+
+// WebhookDefinition is a webhook documented in the spec that can be sent to subscribers using a WebhookDispatcher
+type WebhookDefinition[Req, Resp any] struct {
+	name      string
+	method    string
+	operation *Operation
+	do        func(ctx context.Context, client *Client, payload *Req) (*Resp, error)
+}
+
+// Webhook documents an outgoing webhook in the spec's webhooks using the payload (Req) and subscriber response (Resp)
+// types, the same way routes are documented. The returned definition is used to send the webhook.
+func Webhook[Req, Resp any, ReqPtr WebhookRequestPtr[Req], RespPtr WebhookResponsePtr[Resp]](api *API, name, method string) *WebhookDefinition[Req, Resp] {
+	operation := typedOperation[Req, Resp, ReqPtr, RespPtr](api)
+	if api.openAPISpec.Webhooks == nil {
+		api.openAPISpec.Webhooks = make(map[string]Path)
+	}
+	path := api.openAPISpec.Webhooks[name]
+	path.SetOperation(method, operation)
+	api.openAPISpec.Webhooks[name] = path
+	rebuildAPI(api)
+
+	return &WebhookDefinition[Req, Resp]{
+		name:      name,
+		method:    method,
+		operation: operation,
+		do: func(ctx context.Context, client *Client, payload *Req) (*Resp, error) {
+			return Do[Resp, RespPtr](ctx, client, method, "", ReqPtr(payload))
+		},
+	}
+}
+
+// Name returns the name of the webhook
+func (w *WebhookDefinition[Req, Resp]) Name() string {
+	return w.name
+}
+
+// OpenAPIOperationSpec returns the Operation spec for this webhook
+func (w *WebhookDefinition[Req, Resp]) OpenAPIOperationSpec() *Operation {
+	return w.operation
+}
+
+// WithOperation performs a merge on the operation's spec for this webhook
+func (w *WebhookDefinition[Req, Resp]) WithOperation(op Operation) *WebhookDefinition[Req, Resp] {
+	w.operation.Merge(op)
+	return w
+}
+
+// Send sends the payload to a subscriber's url using dispatcher, failed deliveries are retried
+// (see WebhookDispatcher) and the last error is returned if every attempt fails
+func (w *WebhookDefinition[Req, Resp]) Send(ctx context.Context, dispatcher *WebhookDispatcher, url string, payload *Req) (*Resp, error) {
+	var resp *Resp
+	err := dispatcher.deliver(ctx, url, func(client *Client) error {
+		var err error
+		resp, err = w.do(ctx, client, payload)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WebhookDispatcher sends webhooks to subscribers, retrying failed deliveries with exponential backoff
+type WebhookDispatcher struct {
+	// HTTPClient is the client used to send webhooks (defaults to http.DefaultClient)
+	HTTPClient *http.Client
+	// Header contains headers that are added to every webhook (i.e. a signature or user agent)
+	Header http.Header
+	// MaxAttempts is the most times a webhook is sent (defaults to 5)
+	MaxAttempts int
+	// InitialBackoff is how long to wait before the first retry, it doubles after every retry (defaults to 500ms)
+	InitialBackoff time.Duration
+	// MaxBackoff is the most time to wait between retries (defaults to 30s)
+	MaxBackoff time.Duration
+	// Retryable decides if a failed delivery should be retried, by default transport errors and
+	// 408, 429 and 5XX responses are retried
+	Retryable func(err error) bool
+}
+
+// isRetryableWebhookError is the default WebhookDispatcher.Retryable
+func isRetryableWebhookError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	apiErr := APIError{}
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusRequestTimeout || apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return true
+}
+
+// retryAfter gets the delay requested by a Retry-After header (in seconds)
+func retryAfter(err error) time.Duration {
+	apiErr := APIError{}
+	if errors.As(err, &apiErr) && apiErr.Header != nil {
+		if seconds, e := strconv.Atoi(apiErr.Header.Get("Retry-After")); e == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
+
+// deliver runs attempt until it succeeds, fails with an error that isnt retryable or runs out of attempts
+func (d *WebhookDispatcher) deliver(ctx context.Context, url string, attempt func(*Client) error) error {
+	client := &Client{
+		BaseURL:    url,
+		HTTPClient: d.HTTPClient,
+		Header:     d.Header,
+	}
+	maxAttempts := d.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookAttempts
+	}
+	backoff := d.InitialBackoff
+	if backoff <= 0 {
+		backoff = defaultWebhookInitialBackoff
+	}
+	maxBackoff := d.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultWebhookMaxBackoff
+	}
+	retryable := d.Retryable
+	if retryable == nil {
+		retryable = isRetryableWebhookError
+	}
+
+	var err error
+	for i := 1; ; i++ {
+		err = attempt(client)
+		if err == nil || i >= maxAttempts || !retryable(err) {
+			return err
+		}
+		wait := backoff
+		if after := retryAfter(err); after > wait {
+			wait = after
+		}
+		if wait > maxBackoff {
+			wait = maxBackoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package chimera_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matt1484/chimera"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestWebhookEvent struct {
+	ID   int    `json:"id"`
+	Kind string `json:"kind"`
+}
+
+type TestWebhookParams struct {
+	Signature string `param:"X-Signature,in=header"`
+}
+
+func TestWebhook(t *testing.T) {
+	api := chimera.NewAPI()
+	hook := chimera.Webhook[chimera.JSON[TestWebhookEvent, TestWebhookParams], chimera.EmptyResponse](api, "event", http.MethodPost)
+	hook.WithOperation(chimera.Operation{Summary: "event happened"})
+
+	server := httptest.NewServer(api)
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/openapi.json")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	spec := chimera.OpenAPI{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+	assert.Contains(t, spec.Webhooks, "event")
+	op := spec.Webhooks["event"].Post
+	assert.NotNil(t, op)
+	assert.Equal(t, "event happened", op.Summary)
+	assert.Contains(t, op.RequestBody.Content, "application/json")
+	assert.Equal(t, "#/components/schemas/TestWebhookEvent", op.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Contains(t, spec.Components.Schemas, "TestWebhookEvent")
+	assert.Equal(t, "X-Signature", op.Parameters[0].Name)
+	assert.Contains(t, op.Responses, "200")
+
+	attempts := int32(0)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"id":1,"kind":"created"}`, string(body))
+		assert.Equal(t, "sig", r.Header.Get("X-Signature"))
+		assert.Equal(t, "chimera", r.Header.Get("User-Agent"))
+		assert.Equal(t, "1", r.URL.Query().Get("sub"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	dispatcher := &chimera.WebhookDispatcher{
+		Header:         http.Header{"User-Agent": []string{"chimera"}},
+		InitialBackoff: time.Millisecond,
+	}
+	event := &chimera.JSON[TestWebhookEvent, TestWebhookParams]{
+		Body:   TestWebhookEvent{ID: 1, Kind: "created"},
+		Params: TestWebhookParams{Signature: "sig"},
+	}
+	_, err = hook.Send(context.Background(), dispatcher, receiver.URL+"/hooks?sub=1", event)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+	// gives up after MaxAttempts
+	atomic.StoreInt32(&attempts, -10)
+	dispatcher.MaxAttempts = 2
+	_, err = hook.Send(context.Background(), dispatcher, receiver.URL, event)
+	assert.Equal(t, http.StatusServiceUnavailable, err.(chimera.APIError).StatusCode)
+	assert.Equal(t, int32(-8), atomic.LoadInt32(&attempts))
+
+	// client errors are not retried
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+	atomic.StoreInt32(&attempts, 0)
+	_, err = hook.Send(context.Background(), dispatcher, rejecting.URL, event)
+	assert.Equal(t, http.StatusBadRequest, err.(chimera.APIError).StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}